@@ -2,7 +2,11 @@ package as
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
+	"math/big"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -17,6 +21,9 @@ type As struct {
 	skipCallers  int
 	failDirectly atomic.Bool
 	info         strings.Builder
+	soft         *softCollector
+	clock        Clock
+	caseInfo     []string
 }
 
 // New create a new As object for the specified testing.TB.
@@ -26,6 +33,9 @@ func New(t testing.TB) *As {
 		3,
 		atomic.Bool{},
 		strings.Builder{},
+		nil,
+		nil,
+		nil,
 	}
 }
 
@@ -39,10 +49,7 @@ func (c *As) Equal(got, want interface{}, msgAndArgs ...interface{}) {
 		return
 	}
 	if !objectsAreEqual(want, got) {
-		c.fail("should equal", []string{
-			fmt.Sprintf("want: %v", want),
-			fmt.Sprintf("got: %v", got),
-		}, msgAndArgs...)
+		c.fail("should equal", equalDetails(want, got), msgAndArgs...)
 	}
 }
 
@@ -58,7 +65,7 @@ func (c *As) NotEqual(got, want interface{}, msgAndArgs ...interface{}) {
 
 	if objectsAreEqual(want, got) {
 		c.fail("should not equal", []string{
-			fmt.Sprintf("got: %v", got),
+			"got: " + formatShort(got),
 		}, msgAndArgs...)
 	}
 }
@@ -83,10 +90,370 @@ func (c *As) NoError(err error, msgAndArgs ...interface{}) {
 	}, msgAndArgs...)
 }
 
+// ErrorIs checks that at least one of the errors in err's chain matches
+// target.
+func (c *As) ErrorIs(err, target error, msgAndArgs ...interface{}) {
+	if errors.Is(err, target) {
+		return
+	}
+	c.Helper()
+	c.fail("error chain should contain target", append([]string{
+		fmt.Sprintf("target: %s", formatError(target)),
+		"chain:",
+	}, errorChain(err)...), msgAndArgs...)
+}
+
+// NotErrorIs checks that none of the errors in err's chain matches target.
+func (c *As) NotErrorIs(err, target error, msgAndArgs ...interface{}) {
+	if !errors.Is(err, target) {
+		return
+	}
+	c.Helper()
+	c.fail("error chain should not contain target", append([]string{
+		fmt.Sprintf("target: %s", formatError(target)),
+		"chain:",
+	}, errorChain(err)...), msgAndArgs...)
+}
+
+// ErrorAs checks that at least one of the errors in err's chain matches
+// target, and if so, sets target to that error value. The target must be
+// a non-nil pointer to either a type that implements error, or to any
+// interface type.
+func (c *As) ErrorAs(err error, target interface{}, msgAndArgs ...interface{}) {
+	c.Helper()
+	if e := validateErrorAsTarget(target); e != nil {
+		c.fail("invalid operation", []string{
+			fmt.Sprintf("target: %#v (%s)", target, e),
+		}, msgAndArgs...)
+		return
+	}
+	if errors.As(err, target) {
+		return
+	}
+	c.fail("error chain should contain type of target", append([]string{
+		fmt.Sprintf("target: %s", reflect.TypeOf(target).Elem()),
+		"chain:",
+	}, errorChain(err)...), msgAndArgs...)
+}
+
+// ErrorContains checks that a function returned an error (i.e. not `nil`)
+// and the message of it contains the specified substring.
+func (c *As) ErrorContains(err error, contains string, msgAndArgs ...interface{}) {
+	if err != nil && strings.Contains(err.Error(), contains) {
+		return
+	}
+	c.Helper()
+	if err == nil {
+		c.fail("want an error", []string{
+			fmt.Sprintf("contains: %q", contains),
+		}, msgAndArgs...)
+		return
+	}
+	c.fail("error message should contain", append([]string{
+		fmt.Sprintf("contains: %q", contains),
+		fmt.Sprintf("got: %q", err.Error()),
+		"chain:",
+	}, errorChain(err)...), msgAndArgs...)
+}
+
+// EqualError checks that a function returned an error (i.e. not `nil`)
+// and the message of it equals to the specified string.
+func (c *As) EqualError(err error, want string, msgAndArgs ...interface{}) {
+	if err != nil && err.Error() == want {
+		return
+	}
+	c.Helper()
+	if err == nil {
+		c.fail("want an error", []string{
+			fmt.Sprintf("want: %q", want),
+		}, msgAndArgs...)
+		return
+	}
+	c.fail("error message should equal", append([]string{
+		fmt.Sprintf("want: %q", want),
+		fmt.Sprintf("got: %q", err.Error()),
+		"chain:",
+	}, errorChain(err)...), msgAndArgs...)
+}
+
+// Contains checks that the specified string, slice, array or map contains
+// the specified substring, element or key. Channels are not accepted, since
+// elements are consumed by receiving from them.
+func (c *As) Contains(s, contains interface{}, msgAndArgs ...interface{}) {
+	c.Helper()
+	ok, found := containsElement(s, contains)
+	if !ok {
+		c.fail("invalid operation", []string{
+			fmt.Sprintf("%#v cannot contain %#v", s, contains),
+		}, msgAndArgs...)
+		return
+	}
+	if !found {
+		c.fail("should contain", []string{
+			"contains: " + formatShort(contains),
+			"got: " + formatShort(s),
+		}, msgAndArgs...)
+	}
+}
+
+// NotContains checks that the specified string, slice, array or map does
+// not contain the specified substring, element or key. Channels are not
+// accepted, like Contains.
+func (c *As) NotContains(s, contains interface{}, msgAndArgs ...interface{}) {
+	c.Helper()
+	ok, found := containsElement(s, contains)
+	if !ok {
+		c.fail("invalid operation", []string{
+			fmt.Sprintf("%#v cannot contain %#v", s, contains),
+		}, msgAndArgs...)
+		return
+	}
+	if found {
+		c.fail("should not contain", []string{
+			"contains: " + formatShort(contains),
+			"got: " + formatShort(s),
+		}, msgAndArgs...)
+	}
+}
+
+// Len checks that the specified object has specific length.
+func (c *As) Len(object interface{}, length int, msgAndArgs ...interface{}) {
+	c.Helper()
+	l, ok := getLen(object)
+	if !ok {
+		c.fail("invalid operation", []string{
+			fmt.Sprintf("%#v cannot be applied with builtin len()", object),
+		}, msgAndArgs...)
+		return
+	}
+	if l != length {
+		c.fail("should have length", []string{
+			fmt.Sprintf("want: %d", length),
+			fmt.Sprintf("got: %d", l),
+			"object: " + formatShort(object),
+		}, msgAndArgs...)
+	}
+}
+
+// Empty checks that the specified object is empty, i.e. nil, "", false, 0
+// or a string, slice, array, map or channel with len == 0.
+func (c *As) Empty(object interface{}, msgAndArgs ...interface{}) {
+	if isEmpty(object) {
+		return
+	}
+	c.Helper()
+	c.fail("should be empty", []string{
+		"got: " + formatShort(object),
+	}, msgAndArgs...)
+}
+
+// NotEmpty checks that the specified object is not empty.
+func (c *As) NotEmpty(object interface{}, msgAndArgs ...interface{}) {
+	if !isEmpty(object) {
+		return
+	}
+	c.Helper()
+	c.fail("should not be empty", []string{
+		fmt.Sprintf("got: %#v", object),
+	}, msgAndArgs...)
+}
+
+// ElementsMatch checks that the specified slices or arrays contain the same
+// elements, ignoring the order of them. Duplicated elements must appear the
+// same times. The package level form of it is ElementsMatchAny.
+func (c *As) ElementsMatch(got, want interface{}, msgAndArgs ...interface{}) {
+	c.Helper()
+	if !isList(got) || !isList(want) {
+		c.fail("invalid operation", []string{
+			fmt.Sprintf("%#v and %#v should be slice or array", got, want),
+		}, msgAndArgs...)
+		return
+	}
+	extra, missing := diffLists(got, want)
+	if len(extra) == 0 && len(missing) == 0 {
+		return
+	}
+	details := []string{
+		"want: " + formatShort(want),
+		"got: " + formatShort(got),
+	}
+	if len(missing) > 0 {
+		details = append(details, "missing: "+formatShort(missing))
+	}
+	if len(extra) > 0 {
+		details = append(details, "extra: "+formatShort(extra))
+	}
+	c.fail("elements should match", details, msgAndArgs...)
+}
+
+// Subset checks that the specified slice, array or map contains all the
+// elements of subset. For maps, both keys and values are compared.
+func (c *As) Subset(list, subset interface{}, msgAndArgs ...interface{}) {
+	c.Helper()
+	missing, ok := missingElements(list, subset)
+	if !ok {
+		c.fail("invalid operation", []string{
+			fmt.Sprintf("%#v and %#v should be both slice/array or map", list, subset),
+		}, msgAndArgs...)
+		return
+	}
+	if len(missing) > 0 {
+		c.fail("should be subset", []string{
+			"list: " + formatShort(list),
+			"subset: " + formatShort(subset),
+			"missing: " + formatShort(missing),
+		}, msgAndArgs...)
+	}
+}
+
+// InDelta checks that the specified numbers are within delta of each other.
+// Numbers of any integer or float kinds including time.Duration are accepted.
+func (c *As) InDelta(got, want, delta interface{}, msgAndArgs ...interface{}) {
+	c.Helper()
+	g, okg := toFloat(got)
+	w, okw := toFloat(want)
+	d, okd := toFloat(delta)
+	if !okg || !okw || !okd || d < 0 || math.IsNaN(d) {
+		c.fail("invalid operation", []string{
+			fmt.Sprintf("InDelta(%#v, %#v, %#v) requires numbers and non-negative delta",
+				got, want, delta),
+		}, msgAndArgs...)
+		return
+	}
+	exceeded := math.IsNaN(g) || math.IsNaN(w) || math.Abs(g-w) > d
+	diff := formatLike(want, math.Abs(g-w))
+	gi, okg := toBigInt(got)
+	wi, okw := toBigInt(want)
+	if okg && okw {
+		// compare integers exactly
+		di := new(big.Int).Sub(gi, wi)
+		di.Abs(di)
+		if dd, ok := toBigInt(delta); ok {
+			exceeded = di.Cmp(dd) > 0
+		} else {
+			exceeded = new(big.Float).SetInt(di).Cmp(big.NewFloat(d)) > 0
+		}
+		if _, ok := want.(time.Duration); ok && di.IsInt64() {
+			diff = time.Duration(di.Int64()).String()
+		} else {
+			diff = di.String()
+		}
+	}
+	if exceeded {
+		c.fail("should be in delta", []string{
+			fmt.Sprintf("want %v within ±%v of %v", got, delta, want),
+			fmt.Sprintf("difference: %v", diff),
+		}, msgAndArgs...)
+	}
+}
+
+// InEpsilon checks that the specified numbers have a relative error less
+// than epsilon, i.e. |got - want| / |want| <= epsilon.
+func (c *As) InEpsilon(got, want interface{}, epsilon float64, msgAndArgs ...interface{}) {
+	c.Helper()
+	g, okg := toFloat(got)
+	w, okw := toFloat(want)
+	if !okg || !okw || w == 0 || epsilon < 0 || math.IsNaN(epsilon) {
+		c.fail("invalid operation", []string{
+			fmt.Sprintf("InEpsilon(%#v, %#v, %v) requires numbers, non-zero want and non-negative epsilon",
+				got, want, epsilon),
+		}, msgAndArgs...)
+		return
+	}
+	if rel := math.Abs(g-w) / math.Abs(w); math.IsNaN(rel) || rel > epsilon {
+		c.fail("should be in epsilon", []string{
+			fmt.Sprintf("want %v within ±%v%% of %v", got, epsilon*100, want),
+			fmt.Sprintf("relative error: %v", rel),
+		}, msgAndArgs...)
+	}
+}
+
+// Greater checks that the first element is greater than the second.
+func (c *As) Greater(e1, e2 interface{}, msgAndArgs ...interface{}) {
+	c.Helper()
+	c.compare(e1, e2, ">", func(r int) bool { return r > 0 }, msgAndArgs...)
+}
+
+// GreaterOrEqual checks that the first element is greater than or equal to
+// the second.
+func (c *As) GreaterOrEqual(e1, e2 interface{}, msgAndArgs ...interface{}) {
+	c.Helper()
+	c.compare(e1, e2, ">=", func(r int) bool { return r >= 0 }, msgAndArgs...)
+}
+
+// Less checks that the first element is less than the second.
+func (c *As) Less(e1, e2 interface{}, msgAndArgs ...interface{}) {
+	c.Helper()
+	c.compare(e1, e2, "<", func(r int) bool { return r < 0 }, msgAndArgs...)
+}
+
+// LessOrEqual checks that the first element is less than or equal to the
+// second.
+func (c *As) LessOrEqual(e1, e2 interface{}, msgAndArgs ...interface{}) {
+	c.Helper()
+	c.compare(e1, e2, "<=", func(r int) bool { return r <= 0 }, msgAndArgs...)
+}
+
+// Between checks that the specified element is in the closed interval
+// [min, max].
+func (c *As) Between(got, min, max interface{}, msgAndArgs ...interface{}) {
+	c.Helper()
+	lo, ok1 := compareOrdered(got, min)
+	hi, ok2 := compareOrdered(got, max)
+	if !ok1 || !ok2 {
+		c.fail("invalid operation", []string{
+			fmt.Sprintf("cannot compare %#v with %#v and %#v", got, min, max),
+		}, msgAndArgs...)
+		return
+	}
+	if r, _ := compareOrdered(min, max); r > 0 {
+		c.fail("invalid operation", []string{
+			fmt.Sprintf("min %v is greater than max %v", min, max),
+		}, msgAndArgs...)
+		return
+	}
+	if lo < 0 || hi > 0 {
+		c.fail("should be between", []string{
+			fmt.Sprintf("want %v within [%v, %v]", got, min, max),
+		}, msgAndArgs...)
+	}
+}
+
+// WithinDuration checks that the specified times are within delta of each
+// other.
+func (c *As) WithinDuration(got, want time.Time, delta time.Duration,
+	msgAndArgs ...interface{}) {
+	if d := got.Sub(want); d >= -delta && d <= delta {
+		return
+	}
+	c.Helper()
+	c.fail("should be within duration", []string{
+		fmt.Sprintf("want %v within ±%v of %v", got, delta, want),
+		fmt.Sprintf("difference: %v", got.Sub(want)),
+	}, msgAndArgs...)
+}
+
+func (c *As) compare(e1, e2 interface{}, op string, ok func(r int) bool,
+	msgAndArgs ...interface{}) {
+	c.Helper()
+	r, valid := compareOrdered(e1, e2)
+	if !valid {
+		c.fail("invalid operation", []string{
+			fmt.Sprintf("cannot compare %#v %s %#v", e1, op, e2),
+		}, msgAndArgs...)
+		return
+	}
+	if !ok(r) {
+		c.fail("should be "+op, []string{
+			fmt.Sprintf("want %v %s %v", e1, op, e2),
+		}, msgAndArgs...)
+	}
+}
+
 // Panics checks that the code inside the specified PanicTestFunc panics.
 func (c *As) Panics(f PanicTestFunc, msgAndArgs ...interface{}) {
 	c.Helper()
-	didPanic, panicValue := didPanic(f)
+	didPanic, panicValue, _ := didPanic(f)
 	if !didPanic {
 		c.fail("should panic", []string{
 			fmt.Sprintf("func: %#v", f),
@@ -95,15 +462,85 @@ func (c *As) Panics(f PanicTestFunc, msgAndArgs ...interface{}) {
 	}
 }
 
+// PanicsWithValue checks that the code inside the specified PanicTestFunc
+// panics, and that the recovered panic value equals the specified value.
+func (c *As) PanicsWithValue(want interface{}, f PanicTestFunc, msgAndArgs ...interface{}) {
+	c.Helper()
+	didPanic, panicValue, stack := didPanic(f)
+	if !didPanic {
+		c.fail("should panic", []string{
+			fmt.Sprintf("func: %#v", f),
+			fmt.Sprintf("want: %#v", want),
+		}, msgAndArgs...)
+		return
+	}
+	if !objectsAreEqual(want, panicValue) {
+		c.fail("panic value should equal", append([]string{
+			fmt.Sprintf("want: %#v", want),
+			fmt.Sprintf("got: %#v", panicValue),
+		}, stackDetails(stack)...), msgAndArgs...)
+	}
+}
+
+// PanicsWithError checks that the code inside the specified PanicTestFunc
+// panics, and that the recovered panic value is an error whose message
+// equals the specified string.
+func (c *As) PanicsWithError(want string, f PanicTestFunc, msgAndArgs ...interface{}) {
+	c.Helper()
+	didPanic, panicValue, stack := didPanic(f)
+	if !didPanic {
+		c.fail("should panic", []string{
+			fmt.Sprintf("func: %#v", f),
+			fmt.Sprintf("want: %q", want),
+		}, msgAndArgs...)
+		return
+	}
+	err, ok := panicValue.(error)
+	if !ok || err.Error() != want {
+		c.fail("panic error message should equal", append([]string{
+			fmt.Sprintf("want: %q", want),
+			fmt.Sprintf("got: %#v", panicValue),
+		}, stackDetails(stack)...), msgAndArgs...)
+	}
+}
+
+// PanicsMatching checks that the code inside the specified PanicTestFunc
+// panics, and that the message of the recovered panic value matches the
+// specified regexp.
+func (c *As) PanicsMatching(rx interface{}, f PanicTestFunc, msgAndArgs ...interface{}) {
+	c.Helper()
+	didPanic, panicValue, stack := didPanic(f)
+	if !didPanic {
+		c.fail("should panic", []string{
+			fmt.Sprintf("func: %#v", f),
+			fmt.Sprintf("regex: %v", rx),
+		}, msgAndArgs...)
+		return
+	}
+	message := fmt.Sprint(panicValue)
+	if ok, err := regexMatches(rx, message); !ok {
+		if err != nil {
+			c.fail("failed compiling regex", []string{
+				fmt.Sprintf("%v", rx),
+			}, msgAndArgs...)
+			return
+		}
+		c.fail("panic message should match", append([]string{
+			fmt.Sprintf("regex: %v", rx),
+			fmt.Sprintf("got: %s", message),
+		}, stackDetails(stack)...), msgAndArgs...)
+	}
+}
+
 // NotPanics checks that the code inside the specified PanicTestFunc does not panic.
 func (c *As) NotPanics(f PanicTestFunc, msgAndArgs ...interface{}) {
 	c.Helper()
-	didPanic, panicValue := didPanic(f)
+	didPanic, panicValue, stack := didPanic(f)
 	if didPanic {
-		c.fail("should not panic", []string{
+		c.fail("should not panic", append([]string{
 			fmt.Sprintf("func: %#v", f),
 			fmt.Sprintf("panic value: %#v", panicValue),
-		}, msgAndArgs...)
+		}, stackDetails(stack)...), msgAndArgs...)
 	}
 }
 
@@ -165,23 +602,39 @@ func (c *As) Eventually(condition func(ctx context.Context) bool,
 	waitFor, tick time.Duration, msgAndArgs ...interface{}) {
 	c.Helper()
 
-	ctx, cancel := context.WithTimeout(context.Background(), waitFor)
-	defer cancel()
+	if !c.poll(condition, waitFor, tick) {
+		c.fail("condition not satisfied", nil, msgAndArgs...)
+	}
+}
 
-	tk := time.NewTimer(tick)
-	for {
-		select {
-		case <-ctx.Done():
-			tk.Stop()
-			c.fail("condition not satisfied", nil, msgAndArgs...)
-			return
-		case <-tk.C:
-			if condition(ctx) {
-				return
-			}
-			tk.Reset(tick)
+// EventuallyWithT checks that given condition will be met in waitFor time,
+// periodically checking target function each tick. The condition is met
+// if no check of the As object passed to it fails, otherwise failures of
+// the last attempt are printed.
+func (c *As) EventuallyWithT(condition func(a *As),
+	waitFor, tick time.Duration, msgAndArgs ...interface{}) {
+	c.Helper()
+
+	var last []string
+	if c.poll(func(ctx context.Context) bool {
+		a := newSoft(c.TB)
+		condition(a)
+		last = a.soft.take()
+		return len(last) == 0
+	}, waitFor, tick) {
+		return
+	}
+	var details []string
+	if len(last) > 0 {
+		details = append(details, "last attempt failures:")
+	}
+	for i, f := range last {
+		details = append(details, fmt.Sprintf("%s#%d:", prefix, i+1))
+		for _, line := range strings.Split(strings.TrimSpace(f), "\n") {
+			details = append(details, prefix+line)
 		}
 	}
+	c.fail("condition not satisfied", details, msgAndArgs...)
 }
 
 // Never checks that the given condition doesn't satisfy in waitFor time,
@@ -190,21 +643,52 @@ func (c *As) Never(condition func(ctx context.Context) bool,
 	waitFor, tick time.Duration, msgAndArgs ...interface{}) {
 	c.Helper()
 
-	ctx, cancel := context.WithTimeout(context.Background(), waitFor)
-	defer cancel()
+	if c.poll(condition, waitFor, tick) {
+		c.fail("condition satisfied", nil, msgAndArgs...)
+	}
+}
+
+// poll checks condition each tick until it is satisfied or waitFor time
+// elapsed, returns whether the condition is satisfied. The context passed
+// to condition is done when waitFor time elapsed.
+func (c *As) poll(condition func(ctx context.Context) bool,
+	waitFor, tick time.Duration) bool {
+	clock := c.clock
+	if clock == nil {
+		clock = realClock{}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	exited := make(chan struct{})
+	defer func() {
+		// wait for the goroutine below, so it is never seen by callers
+		cancel()
+		<-exited
+	}()
 
-	tk := time.NewTimer(tick)
+	deadline := clock.NewTimer(waitFor)
+	defer deadline.Stop()
+	go func() {
+		defer close(exited)
+		select {
+		case <-deadline.C():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	tk := clock.NewTimer(tick)
+	defer func() {
+		tk.Stop()
+	}()
 	for {
 		select {
 		case <-ctx.Done():
-			tk.Stop()
-			return
-		case <-tk.C:
+			return false
+		case <-tk.C():
 			if condition(ctx) {
-				c.fail("condition satisfied", nil, msgAndArgs...)
-				return
+				return true
 			}
-			tk.Reset(tick)
+			tk = clock.NewTimer(tick)
 		}
 	}
 }
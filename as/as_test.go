@@ -4,6 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -23,11 +28,104 @@ func TestBasic(t *testing.T) {
 	assertOk(t, "NoError", func(t testing.TB) {
 		as.NoError(t, nil)
 	})
+	assertOk(t, "ErrorIs", func(t testing.TB) {
+		as.ErrorIs(t, fmt.Errorf("wrap: %w", io.EOF), io.EOF)
+	})
+	assertOk(t, "NotErrorIs", func(t testing.TB) {
+		as.NotErrorIs(t, errors.New("sth wrong"), io.EOF)
+	})
+	assertOk(t, "ErrorAs", func(t testing.TB) {
+		var pe *os.PathError
+		as.ErrorAs(t, fmt.Errorf("wrap: %w", &os.PathError{Op: "open"}), &pe)
+		as.Equal(t, pe.Op, "open")
+	})
+	assertOk(t, "ErrorContains", func(t testing.TB) {
+		as.ErrorContains(t, errors.New("sth wrong"), "wrong")
+	})
+	assertOk(t, "EqualError", func(t testing.TB) {
+		as.EqualError(t, errors.New("sth wrong"), "sth wrong")
+	})
+	assertOk(t, "Contains", func(t testing.TB) {
+		as.Contains(t, "hello world", "world")
+		as.Contains(t, []int{1, 2}, 2)
+		as.Contains(t, [2]string{"a", "b"}, "a")
+		as.Contains(t, map[string]int{"a": 1}, "a")
+	})
+	assertOk(t, "NotContains", func(t testing.TB) {
+		as.NotContains(t, "hello world", "foo")
+		as.NotContains(t, []int{1, 2}, 3)
+		as.NotContains(t, map[string]int{"a": 1}, "b")
+	})
+	assertOk(t, "Len", func(t testing.TB) {
+		as.Len(t, "abc", 3)
+		as.Len(t, []int{1, 2}, 2)
+		as.Len(t, map[int]int{1: 1}, 1)
+		ch := make(chan int, 2)
+		ch <- 1
+		as.Len(t, ch, 1)
+	})
+	assertOk(t, "Empty", func(t testing.TB) {
+		as.Empty(t, nil)
+		as.Empty(t, "")
+		as.Empty(t, []int{})
+		as.Empty(t, make(chan int))
+		as.Empty(t, struct{ A int }{})
+	})
+	assertOk(t, "NotEmpty", func(t testing.TB) {
+		as.NotEmpty(t, "a")
+		as.NotEmpty(t, map[int]int{1: 1})
+	})
+	assertOk(t, "ElementsMatch", func(t testing.TB) {
+		as.ElementsMatchAny(t, []interface{}{1, "a", 1}, [3]interface{}{"a", 1, 1})
+	})
+	assertOk(t, "Subset", func(t testing.TB) {
+		as.Subset(t, []int{1, 2, 3}, []int{3, 1})
+		as.Subset(t, map[string]int{"a": 1, "b": 2}, map[string]int{"b": 2})
+		as.Subset(t, map[string]int{"a": 1}, map[interface{}]int{"a": 1})
+	})
+	assertOk(t, "InDelta", func(t testing.TB) {
+		as.InDelta(t, 1.05, 1, 0.1)
+		as.InDelta(t, uint8(9), int64(10), 1)
+		as.InDelta(t, time.Millisecond*1050, time.Second, time.Millisecond*50)
+	})
+	assertOk(t, "InEpsilon", func(t testing.TB) {
+		as.InEpsilon(t, 102, 100, 0.02)
+	})
+	assertOk(t, "Compare", func(t testing.TB) {
+		as.Greater(t, 2, 1)
+		as.GreaterOrEqual(t, uint(2), uint(2))
+		as.Less(t, "a", "b")
+		as.LessOrEqual(t, time.Second, time.Second)
+		as.Less(t, time.Unix(1, 0), time.Unix(2, 0))
+	})
+	assertOk(t, "Between", func(t testing.TB) {
+		as.Between(t, 1.5, 1.0, 2.0)
+		as.Between(t, time.Second, time.Second, time.Minute)
+	})
+	assertOk(t, "WithinDuration", func(t testing.TB) {
+		now := time.Now()
+		as.WithinDuration(t, now.Add(-time.Second), now, time.Second)
+	})
 	assertOk(t, "Panics", func(t testing.TB) {
 		as.Panics(t, func() {
 			panic(0)
 		})
 	})
+	assertOk(t, "PanicsWithValue", func(t testing.TB) {
+		as.PanicsWithValue(t, "pool: close of a closed worker pool", func() {
+			panic("pool: close of a closed worker pool")
+		})
+	})
+	assertOk(t, "PanicsWithError", func(t testing.TB) {
+		as.PanicsWithError(t, "sth wrong", func() {
+			panic(errors.New("sth wrong"))
+		})
+	})
+	assertOk(t, "PanicsMatching", func(t testing.TB) {
+		as.PanicsMatching(t, "^semaphore: ", func() {
+			panic("semaphore: bad release")
+		})
+	})
 	assertOk(t, "NotPanics", func(t testing.TB) {
 		as.NotPanics(t, func() {})
 	})
@@ -70,14 +168,119 @@ func TestFail(t *testing.T) {
 	assertFail(t, "NoError", func(t testing.TB) {
 		as.NoError(t, errors.New("sth wrong"))
 	})
+	assertFailWith(t, "ErrorIs", func(t testing.TB) {
+		as.ErrorIs(t, fmt.Errorf("wrap: %w", io.ErrUnexpectedEOF), io.EOF)
+	}, `*fmt.wrapError: "wrap: unexpected EOF"`,
+		`  *errors.errorString: "unexpected EOF"`)
+	assertFail(t, "NotErrorIs", func(t testing.TB) {
+		as.NotErrorIs(t, fmt.Errorf("wrap: %w", io.EOF), io.EOF)
+	})
+	assertFail(t, "ErrorAs", func(t testing.TB) {
+		var pe *os.PathError
+		as.ErrorAs(t, errors.New("sth wrong"), &pe)
+	})
+	assertFailWith(t, "ErrorAsInvalid", func(t testing.TB) {
+		as.ErrorAs(t, errors.New("sth wrong"), nil)
+	}, "invalid operation")
+	assertFail(t, "ErrorContains", func(t testing.TB) {
+		as.ErrorContains(t, errors.New("sth wrong"), "right")
+	})
+	assertFail(t, "EqualError", func(t testing.TB) {
+		as.EqualError(t, nil, "sth wrong")
+	})
+	assertFail(t, "Contains", func(t testing.TB) {
+		as.Contains(t, []int{1, 2}, 3)
+	})
+	assertFailWith(t, "ContainsInvalid", func(t testing.TB) {
+		as.Contains(t, 1, 1)
+	}, "invalid operation")
+	assertFail(t, "NotContains", func(t testing.TB) {
+		as.NotContains(t, "hello world", "world")
+	})
+	assertFail(t, "Len", func(t testing.TB) {
+		as.Len(t, []int{1, 2}, 3)
+	})
+	assertFail(t, "Empty", func(t testing.TB) {
+		as.Empty(t, []int{1})
+	})
+	assertFail(t, "NotEmpty", func(t testing.TB) {
+		as.NotEmpty(t, map[int]int{})
+	})
+	assertFailWith(t, "ElementsMatch", func(t testing.TB) {
+		as.ElementsMatchAny(t, []string{"a", "b"}, []string{"b", "c"})
+	}, "missing: [c]", "extra: [a]")
+	assertFailWith(t, "Subset", func(t testing.TB) {
+		as.Subset(t, []int{1, 2, 3}, []int{3, 4})
+	}, "missing: [4]")
+	assertFailWith(t, "SubsetMap", func(t testing.TB) {
+		as.Subset(t, map[string]int{"a": 1}, map[string]int{"a": 2})
+	}, "missing: [a:2]")
+	assertFailWith(t, "SubsetMapKeyType", func(t testing.TB) {
+		as.Subset(t, map[string]int{"a": 1}, map[int]int{1: 1})
+	}, "missing: [1:1]")
+	assertFail(t, "ContainsChan", func(t testing.TB) {
+		as.Contains(t, make(chan int), 1)
+	})
+	assertFailWith(t, "InDelta", func(t testing.TB) {
+		as.InDelta(t, time.Millisecond*1100, time.Second, time.Millisecond*50)
+	}, "want 1.1s within ±50ms of 1s", "difference: 100ms")
+	assertFailWith(t, "InDeltaInt64", func(t testing.TB) {
+		as.InDelta(t, int64(1<<62), int64(1<<62+1), 0)
+	}, "difference: 1")
+	assertFailWith(t, "InDeltaInvalid", func(t testing.TB) {
+		as.InDelta(t, "1", 1, 1)
+	}, "invalid operation")
+	assertFail(t, "InEpsilon", func(t testing.TB) {
+		as.InEpsilon(t, 110, 100, 0.05)
+	})
+	assertFailWith(t, "Greater", func(t testing.TB) {
+		as.Greater(t, 1, 2)
+	}, "want 1 > 2")
+	assertFail(t, "GreaterOrEqual", func(t testing.TB) {
+		as.GreaterOrEqual(t, 1.0, 1.5)
+	})
+	assertFail(t, "Less", func(t testing.TB) {
+		as.Less(t, time.Second, time.Second)
+	})
+	assertFail(t, "LessOrEqual", func(t testing.TB) {
+		as.LessOrEqual(t, "b", "a")
+	})
+	assertFailWith(t, "CompareInvalid", func(t testing.TB) {
+		as.Less(t, 1, int64(2))
+	}, "invalid operation")
+	assertFailWith(t, "Between", func(t testing.TB) {
+		as.Between(t, 3, 1, 2)
+	}, "want 3 within [1, 2]")
+	assertFailWith(t, "BetweenInvalid", func(t testing.TB) {
+		as.Between(t, 1, 2, 1)
+	}, "min 2 is greater than max 1")
+	assertFail(t, "WithinDuration", func(t testing.TB) {
+		now := time.Now()
+		as.WithinDuration(t, now.Add(time.Minute), now, time.Second)
+	})
 	assertFail(t, "Panics", func(t testing.TB) {
 		as.Panics(t, func() {})
 	})
-	assertFail(t, "NotPanics", func(t testing.TB) {
+	assertFailWith(t, "NotPanics", func(t testing.TB) {
 		as.NotPanics(t, func() {
 			panic(0)
 		})
+	}, "panic stack:", "as_test.go")
+	assertFail(t, "PanicsWithValue", func(t testing.TB) {
+		as.PanicsWithValue(t, "sth wrong", func() {
+			panic("sth else")
+		})
+	})
+	assertFail(t, "PanicsWithError", func(t testing.TB) {
+		as.PanicsWithError(t, "sth wrong", func() {
+			panic("sth wrong")
+		})
 	})
+	assertFailWith(t, "PanicsMatching", func(t testing.TB) {
+		as.PanicsMatching(t, "^pool: ", func() {
+			panic("semaphore: bad release")
+		})
+	}, "got: semaphore: bad release", "panic stack:")
 	assertFail(t, "Regexp", func(t testing.TB) {
 		as.Regexp(t, "^asdfastart", "Not the start of the line")
 	})
@@ -104,6 +307,34 @@ func TestFail(t *testing.T) {
 	})
 }
 
+func TestEqualDiff(t *testing.T) {
+	type item struct {
+		Name string
+		tags map[string]int
+	}
+	type order struct {
+		Items []item
+	}
+	want := order{Items: []item{
+		{Name: "a", tags: map[string]int{"x": 1}},
+		{Name: "b"},
+	}}
+	got := order{Items: []item{
+		{Name: "a", tags: map[string]int{"x": 2}},
+		{Name: "c"},
+		{Name: "d"},
+	}}
+	assertFailWith(t, "Struct", func(t testing.TB) {
+		as.Equal(t, got, want)
+	}, `.Items[0].tags["x"]: 1 != 2`,
+		`.Items[1].Name: "b" != "c"`,
+		`.Items[2]: <missing> != `)
+
+	assertFailWith(t, "Truncate", func(t testing.TB) {
+		as.Equal(t, strings.Repeat("a", 1000), strings.Repeat("b", 1000))
+	}, "(744 bytes truncated)")
+}
+
 func TestFailDirectly(t *testing.T) {
 	assertFail(t, "True", func(t testing.TB) {
 		tf := as.New(t)
@@ -115,6 +346,113 @@ func TestFailDirectly(t *testing.T) {
 	})
 }
 
+func TestEventually(t *testing.T) {
+	assertOk(t, "EventuallyWithT", func(t testing.TB) {
+		var n int32
+		as.EventuallyWithT(t, func(a *as.As) {
+			a.Equal(atomic.AddInt32(&n, 1), int32(3))
+		}, time.Second, time.Millisecond*10)
+	})
+	assertFailWith(t, "EventuallyWithT", func(t testing.TB) {
+		as.EventuallyWithT(t, func(a *as.As) {
+			a.True(true)
+			a.Equal(1, 2, "never equal")
+		}, time.Millisecond*100, time.Millisecond*10)
+	}, "last attempt failures:", "#1:", "should equal", "never equal")
+
+	assertOk(t, "FakeClock", func(t testing.TB) {
+		clk := as.NewFakeClock(time.Unix(0, 0))
+		var n int32
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			as.New(t).WithClock(clk).Eventually(func(ctx context.Context) bool {
+				return atomic.AddInt32(&n, 1) == 3
+			}, time.Hour, time.Minute)
+		}()
+		for i := 0; i < 3; i++ {
+			clk.BlockUntil(2)
+			clk.Advance(time.Minute)
+		}
+		<-done
+		as.Equal(t, clk.Now(), time.Unix(0, 0).Add(time.Minute*3))
+
+		// timers are stopped after returned, so they are no longer waiters
+		blocked := make(chan struct{})
+		go func() {
+			defer close(blocked)
+			clk.BlockUntil(1)
+		}()
+		select {
+		case <-blocked:
+			t.Error("Should have no waiter after returned")
+		case <-time.After(time.Millisecond * 50):
+		}
+		tm := clk.NewTimer(time.Minute)
+		<-blocked
+		as.True(t, tm.Stop())
+		as.False(t, tm.Stop())
+	})
+	assertFail(t, "FakeClock", func(t testing.TB) {
+		clk := as.NewFakeClock(time.Unix(0, 0))
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			as.New(t).WithClock(clk).Never(func(ctx context.Context) bool {
+				return true
+			}, time.Hour, time.Minute)
+		}()
+		clk.BlockUntil(2)
+		clk.Advance(time.Minute)
+		<-done
+	})
+}
+
+func TestSoft(t *testing.T) {
+	tester := &testTester{}
+	t.Run("Soft", func(t *testing.T) {
+		tester.T = t
+		a := as.Soft(tester)
+		a.True(false)
+		a.Equal(1, 2)
+		if tester.errorMsg != "" {
+			t.Fatal("Should not have failed before test finished")
+		}
+	})
+	for _, s := range []string{"2 failure(s)", "#1:", "should be true", "#2:", "should equal"} {
+		if !strings.Contains(tester.errorMsg, s) {
+			t.Fatalf("Should have failed with %q, got:\n%s", s, tester.errorMsg)
+		}
+	}
+
+	assertOk(t, "Collect", func(t testing.TB) {
+		as.New(t).Collect(func(a *as.As) {
+			a.True(true)
+			a.Equal(1, 1)
+		})
+	})
+	assertFailWith(t, "Collect", func(t testing.TB) {
+		as.New(t).Collect(func(a *as.As) {
+			a.True(false)
+			a.Equal(1, 1)
+			a.Equal(1, 2)
+		})
+	}, "2 failure(s)", "#1:", "should be true", "#2:", "should equal")
+	assertFailWith(t, "CollectConcurrently", func(t testing.TB) {
+		as.New(t).Collect(func(a *as.As) {
+			var wg sync.WaitGroup
+			for i := 0; i < 4; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					a.True(false)
+				}()
+			}
+			wg.Wait()
+		})
+	}, "4 failure(s)", "as_test.go")
+}
+
 type testTester struct {
 	*testing.T
 	errorMsg   string
@@ -151,6 +489,25 @@ func assertOk(t *testing.T, name string, fn func(t testing.TB)) {
 	})
 }
 
+func assertFailWith(t *testing.T, name string, fn func(t testing.TB),
+	contains ...string) {
+	t.Helper()
+	t.Run(name, func(t *testing.T) {
+		t.Helper()
+		tester := &testTester{T: t}
+		fn(tester)
+		if tester.errorMsg == "" {
+			t.Fatal("Should have failed")
+		}
+		for _, s := range contains {
+			if !strings.Contains(tester.errorMsg, s) {
+				t.Fatalf("Should have failed with %q, got:\n%s", s, tester.errorMsg)
+			}
+		}
+		t.Log(tester.errorMsg)
+	})
+}
+
 func assertFail(t *testing.T, name string, fn func(t testing.TB)) {
 	t.Helper()
 	t.Run(name, func(t *testing.T) {
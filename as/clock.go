@@ -0,0 +1,120 @@
+package as
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock provides the time for checks which wait, e.g. Eventually and Never.
+type Clock interface {
+	Now() time.Time
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer is a timer created by Clock, like time.Timer.
+type Timer interface {
+	// C returns the channel on which the time is sent when timer fires.
+	C() <-chan time.Time
+	// Stop prevents the timer from firing, returns false if the timer has
+	// already fired or been stopped.
+	Stop() bool
+}
+
+// realClock is a wrapper around time.Now and time.NewTimer.
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+func (realClock) NewTimer(d time.Duration) Timer { return realTimer{time.NewTimer(d)} }
+
+type realTimer struct {
+	*time.Timer
+}
+
+func (t realTimer) C() <-chan time.Time { return t.Timer.C }
+
+// FakeClock is a Clock which only moves forward when Advance is called,
+// so that checks like Eventually and Never run without real sleeps.
+type FakeClock struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	now     time.Time
+	waiters []*fakeTimer
+}
+
+type fakeTimer struct {
+	f  *FakeClock
+	at time.Time
+	c  chan time.Time
+}
+
+// NewFakeClock create a new FakeClock starts at the specified time.
+func NewFakeClock(now time.Time) *FakeClock {
+	f := &FakeClock{now: now}
+	f.cond = sync.NewCond(&f.mu)
+	return f
+}
+
+// Now returns the current time of the clock.
+func (f *FakeClock) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// NewTimer create a timer which waits for the clock being advanced by d,
+// and then sends the current time of the clock on its channel.
+func (f *FakeClock) NewTimer(d time.Duration) Timer {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	t := &fakeTimer{f: f, at: f.now.Add(d), c: make(chan time.Time, 1)}
+	if d <= 0 {
+		t.c <- f.now
+		return t
+	}
+	f.waiters = append(f.waiters, t)
+	f.cond.Broadcast()
+	return t
+}
+
+// Advance moves the clock forward by d, firing all the waiters which are
+// due at the new time.
+func (f *FakeClock) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = f.now.Add(d)
+	waiters := f.waiters[:0]
+	for _, w := range f.waiters {
+		if w.at.After(f.now) {
+			waiters = append(waiters, w)
+			continue
+		}
+		w.c <- f.now
+	}
+	f.waiters = waiters
+}
+
+// BlockUntil blocks until there are at least n waiters on the clock, which
+// is useful to advance the clock only after a check starts waiting. Timers
+// which are fired or stopped are not waiters.
+func (f *FakeClock) BlockUntil(n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for len(f.waiters) < n {
+		f.cond.Wait()
+	}
+}
+
+func (t *fakeTimer) C() <-chan time.Time { return t.c }
+
+func (t *fakeTimer) Stop() bool {
+	t.f.mu.Lock()
+	defer t.f.mu.Unlock()
+	for i, w := range t.f.waiters {
+		if w == t {
+			t.f.waiters = append(t.f.waiters[:i], t.f.waiters[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
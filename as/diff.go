@@ -0,0 +1,255 @@
+package as
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// maxDiffLines is the max number of differences reported by diff.
+	maxDiffLines = 32
+	// maxValueLen is the max length of a formatted value in failure output.
+	maxValueLen = 256
+)
+
+// equalDetails returns the details for a failure of equality check.
+func equalDetails(want, got interface{}) []string {
+	return append([]string{
+		"want: " + formatShort(want),
+		"got: " + formatShort(got),
+	}, diffDetails(want, got)...)
+}
+
+// diffDetails returns the details of a structural diff between want and got,
+// or nil if the values are not composite and a diff is of no help.
+func diffDetails(want, got interface{}) []string {
+	if !isComposite(want) && !isComposite(got) {
+		return nil
+	}
+	lines := diff(want, got)
+	if len(lines) == 0 {
+		return nil
+	}
+	details := make([]string, 0, len(lines)+1)
+	details = append(details, "diff (want != got):")
+	for _, line := range lines {
+		details = append(details, prefix+line)
+	}
+	return details
+}
+
+func isComposite(v interface{}) bool {
+	if v == nil {
+		return false
+	}
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+		return true
+	}
+	return false
+}
+
+// diff walks want and got, and returns a path-annotated line for each
+// difference, e.g. `.Items[3].Name: "a" != "b"`.
+func diff(want, got interface{}) []string {
+	d := differ{visited: make(map[visit]bool)}
+	d.walk("", reflect.ValueOf(want), reflect.ValueOf(got))
+	if d.omitted > 0 {
+		d.lines = append(d.lines,
+			fmt.Sprintf("... (%d more differences)", d.omitted))
+	}
+	return d.lines
+}
+
+// visit records a pair of pointers which had been compared, so that
+// cyclic data structures will not be walked forever.
+type visit struct {
+	want, got uintptr
+	typ       reflect.Type
+}
+
+type differ struct {
+	lines   []string
+	omitted int
+	visited map[visit]bool
+}
+
+func (d *differ) report(path string, want, got string) {
+	if len(d.lines) >= maxDiffLines {
+		d.omitted++
+		return
+	}
+	if path == "" {
+		path = "value"
+	}
+	d.lines = append(d.lines, fmt.Sprintf("%s: %s != %s", path, want, got))
+}
+
+func (d *differ) walk(path string, want, got reflect.Value) {
+	if !want.IsValid() || !got.IsValid() {
+		if want.IsValid() != got.IsValid() {
+			d.report(path, formatValue(want), formatValue(got))
+		}
+		return
+	}
+	if want.Type() != got.Type() {
+		d.report(path,
+			formatValue(want)+" ("+want.Type().String()+")",
+			formatValue(got)+" ("+got.Type().String()+")")
+		return
+	}
+
+	switch want.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice:
+		if want.IsNil() || got.IsNil() {
+			if want.IsNil() != got.IsNil() {
+				d.report(path, formatValue(want), formatValue(got))
+			}
+			return
+		}
+		if want.Pointer() == got.Pointer() &&
+			(want.Kind() != reflect.Slice || want.Len() == got.Len()) {
+			return
+		}
+		v := visit{want.Pointer(), got.Pointer(), want.Type()}
+		if d.visited[v] {
+			return
+		}
+		d.visited[v] = true
+	}
+
+	switch want.Kind() {
+	case reflect.Ptr:
+		d.walk(path, want.Elem(), got.Elem())
+	case reflect.Interface:
+		if want.IsNil() || got.IsNil() {
+			if want.IsNil() != got.IsNil() {
+				d.report(path, formatValue(want), formatValue(got))
+			}
+			return
+		}
+		d.walk(path, want.Elem(), got.Elem())
+	case reflect.Struct:
+		t := want.Type()
+		for i := 0; i < want.NumField(); i++ {
+			d.walk(path+"."+t.Field(i).Name, want.Field(i), got.Field(i))
+		}
+	case reflect.Slice, reflect.Array:
+		n := want.Len()
+		if got.Len() > n {
+			n = got.Len()
+		}
+		for i := 0; i < n; i++ {
+			p := fmt.Sprintf("%s[%d]", path, i)
+			switch {
+			case i >= want.Len():
+				d.report(p, "<missing>", formatValue(got.Index(i)))
+			case i >= got.Len():
+				d.report(p, formatValue(want.Index(i)), "<missing>")
+			default:
+				d.walk(p, want.Index(i), got.Index(i))
+			}
+		}
+	case reflect.Map:
+		for _, k := range sortedKeys(want, got) {
+			p := fmt.Sprintf("%s[%s]", path, formatValue(k))
+			wv, gv := want.MapIndex(k), got.MapIndex(k)
+			switch {
+			case !wv.IsValid():
+				d.report(p, "<missing>", formatValue(gv))
+			case !gv.IsValid():
+				d.report(p, formatValue(wv), "<missing>")
+			default:
+				d.walk(p, wv, gv)
+			}
+		}
+	case reflect.Func:
+		if !want.IsNil() || !got.IsNil() {
+			d.report(path, formatValue(want), formatValue(got))
+		}
+	default:
+		if !basicEqual(want, got) {
+			d.report(path, formatValue(want), formatValue(got))
+		}
+	}
+}
+
+// basicEqual compares values of basic kinds without calling Interface,
+// which panics on values obtained from unexported fields.
+func basicEqual(want, got reflect.Value) bool {
+	switch want.Kind() {
+	case reflect.Bool:
+		return want.Bool() == got.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return want.Int() == got.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr:
+		return want.Uint() == got.Uint()
+	case reflect.Float32, reflect.Float64:
+		return want.Float() == got.Float()
+	case reflect.Complex64, reflect.Complex128:
+		return want.Complex() == got.Complex()
+	case reflect.String:
+		return want.String() == got.String()
+	case reflect.Chan, reflect.UnsafePointer:
+		return want.Pointer() == got.Pointer()
+	}
+	return true
+}
+
+// sortedKeys returns the union of keys of two maps in a stable order.
+func sortedKeys(a, b reflect.Value) []reflect.Value {
+	keys := make([]reflect.Value, 0, a.Len())
+	seen := make(map[string]bool, a.Len())
+	for _, m := range []reflect.Value{a, b} {
+		iter := m.MapRange()
+		for iter.Next() {
+			k := iter.Key()
+			s := fmt.Sprintf("%#v", k)
+			if seen[s] {
+				continue
+			}
+			seen[s] = true
+			keys = append(keys, k)
+		}
+	}
+	sort.SliceStable(keys, func(i, j int) bool {
+		return fmt.Sprintf("%v", keys[i]) < fmt.Sprintf("%v", keys[j])
+	})
+	return keys
+}
+
+// formatValue formats v in the Go syntax, with huge values truncated.
+func formatValue(v reflect.Value) string {
+	if !v.IsValid() {
+		return "<nil>"
+	}
+	return truncate(fmt.Sprintf("%#v", v))
+}
+
+// formatShort formats v in the default format, with huge values truncated.
+func formatShort(v interface{}) string {
+	return truncate(fmt.Sprintf("%v", v))
+}
+
+// truncate shortens s to maxValueLen, marking how many bytes are dropped.
+func truncate(s string) string {
+	if len(s) <= maxValueLen {
+		return s
+	}
+	n := maxValueLen
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	var sb strings.Builder
+	sb.WriteString(s[:n])
+	fmt.Fprintf(&sb, "... (%d bytes truncated)", len(s)-n)
+	return sb.String()
+}
@@ -16,6 +16,9 @@ func directlyAs(t testing.TB) *As {
 		4,
 		atomic.Bool{},
 		strings.Builder{},
+		nil,
+		nil,
+		nil,
 	}
 }
 
@@ -43,12 +46,175 @@ func NoError(t testing.TB, err error, msgAndArgs ...interface{}) {
 	directlyAs(t).NoError(err, msgAndArgs...)
 }
 
+// ErrorIs asserts that at least one of the errors in err's chain matches
+// target.
+func ErrorIs(t testing.TB, err, target error, msgAndArgs ...interface{}) {
+	t.Helper()
+	directlyAs(t).ErrorIs(err, target, msgAndArgs...)
+}
+
+// NotErrorIs asserts that none of the errors in err's chain matches target.
+func NotErrorIs(t testing.TB, err, target error, msgAndArgs ...interface{}) {
+	t.Helper()
+	directlyAs(t).NotErrorIs(err, target, msgAndArgs...)
+}
+
+// ErrorAs asserts that at least one of the errors in err's chain matches
+// target, and if so, sets target to that error value.
+func ErrorAs(t testing.TB, err error, target interface{}, msgAndArgs ...interface{}) {
+	t.Helper()
+	directlyAs(t).ErrorAs(err, target, msgAndArgs...)
+}
+
+// ErrorContains asserts that a function returned an error (i.e. not `nil`)
+// and the message of it contains the specified substring.
+func ErrorContains(t testing.TB, err error, contains string, msgAndArgs ...interface{}) {
+	t.Helper()
+	directlyAs(t).ErrorContains(err, contains, msgAndArgs...)
+}
+
+// EqualError asserts that a function returned an error (i.e. not `nil`)
+// and the message of it equals to the specified string.
+func EqualError(t testing.TB, err error, want string, msgAndArgs ...interface{}) {
+	t.Helper()
+	directlyAs(t).EqualError(err, want, msgAndArgs...)
+}
+
+// Contains asserts that the specified string, slice, array or map contains
+// the specified substring, element or key. Channels are not accepted, since
+// elements are consumed by receiving from them.
+func Contains(t testing.TB, s, contains interface{}, msgAndArgs ...interface{}) {
+	t.Helper()
+	directlyAs(t).Contains(s, contains, msgAndArgs...)
+}
+
+// NotContains asserts that the specified string, slice, array or map does
+// not contain the specified substring, element or key. Channels are not
+// accepted, like Contains.
+func NotContains(t testing.TB, s, contains interface{}, msgAndArgs ...interface{}) {
+	t.Helper()
+	directlyAs(t).NotContains(s, contains, msgAndArgs...)
+}
+
+// Len asserts that the specified object has specific length.
+func Len(t testing.TB, object interface{}, length int, msgAndArgs ...interface{}) {
+	t.Helper()
+	directlyAs(t).Len(object, length, msgAndArgs...)
+}
+
+// Empty asserts that the specified object is empty, i.e. nil, "", false, 0
+// or a string, slice, array, map or channel with len == 0.
+func Empty(t testing.TB, object interface{}, msgAndArgs ...interface{}) {
+	t.Helper()
+	directlyAs(t).Empty(object, msgAndArgs...)
+}
+
+// NotEmpty asserts that the specified object is not empty.
+func NotEmpty(t testing.TB, object interface{}, msgAndArgs ...interface{}) {
+	t.Helper()
+	directlyAs(t).NotEmpty(object, msgAndArgs...)
+}
+
+// ElementsMatchAny asserts that the specified slices or arrays contain the
+// same elements, ignoring the order of them. Duplicated elements must appear
+// the same times. Unlike the generic ElementsMatch, the elements may be of
+// different types, e.g. []interface{}, and arrays are accepted.
+func ElementsMatchAny(t testing.TB, got, want interface{}, msgAndArgs ...interface{}) {
+	t.Helper()
+	directlyAs(t).ElementsMatch(got, want, msgAndArgs...)
+}
+
+// Subset asserts that the specified slice, array or map contains all the
+// elements of subset. For maps, both keys and values are compared.
+func Subset(t testing.TB, list, subset interface{}, msgAndArgs ...interface{}) {
+	t.Helper()
+	directlyAs(t).Subset(list, subset, msgAndArgs...)
+}
+
+// InDelta asserts that the specified numbers are within delta of each other.
+// Numbers of any integer or float kinds including time.Duration are accepted.
+func InDelta(t testing.TB, got, want, delta interface{}, msgAndArgs ...interface{}) {
+	t.Helper()
+	directlyAs(t).InDelta(got, want, delta, msgAndArgs...)
+}
+
+// InEpsilon asserts that the specified numbers have a relative error less
+// than epsilon, i.e. |got - want| / |want| <= epsilon.
+func InEpsilon(t testing.TB, got, want interface{}, epsilon float64, msgAndArgs ...interface{}) {
+	t.Helper()
+	directlyAs(t).InEpsilon(got, want, epsilon, msgAndArgs...)
+}
+
+// Greater asserts that the first element is greater than the second.
+func Greater(t testing.TB, e1, e2 interface{}, msgAndArgs ...interface{}) {
+	t.Helper()
+	directlyAs(t).Greater(e1, e2, msgAndArgs...)
+}
+
+// GreaterOrEqual asserts that the first element is greater than or equal to
+// the second.
+func GreaterOrEqual(t testing.TB, e1, e2 interface{}, msgAndArgs ...interface{}) {
+	t.Helper()
+	directlyAs(t).GreaterOrEqual(e1, e2, msgAndArgs...)
+}
+
+// Less asserts that the first element is less than the second.
+func Less(t testing.TB, e1, e2 interface{}, msgAndArgs ...interface{}) {
+	t.Helper()
+	directlyAs(t).Less(e1, e2, msgAndArgs...)
+}
+
+// LessOrEqual asserts that the first element is less than or equal to the
+// second.
+func LessOrEqual(t testing.TB, e1, e2 interface{}, msgAndArgs ...interface{}) {
+	t.Helper()
+	directlyAs(t).LessOrEqual(e1, e2, msgAndArgs...)
+}
+
+// Between asserts that the specified element is in the closed interval
+// [min, max].
+func Between(t testing.TB, got, min, max interface{}, msgAndArgs ...interface{}) {
+	t.Helper()
+	directlyAs(t).Between(got, min, max, msgAndArgs...)
+}
+
+// WithinDuration asserts that the specified times are within delta of each
+// other.
+func WithinDuration(t testing.TB, got, want time.Time, delta time.Duration,
+	msgAndArgs ...interface{}) {
+	t.Helper()
+	directlyAs(t).WithinDuration(got, want, delta, msgAndArgs...)
+}
+
 // Panics asserts that the code inside the specified PanicTestFunc panics.
 func Panics(t testing.TB, f PanicTestFunc, msgAndArgs ...interface{}) {
 	t.Helper()
 	directlyAs(t).Panics(f, msgAndArgs...)
 }
 
+// PanicsWithValue asserts that the code inside the specified PanicTestFunc
+// panics, and that the recovered panic value equals the specified value.
+func PanicsWithValue(t testing.TB, want interface{}, f PanicTestFunc, msgAndArgs ...interface{}) {
+	t.Helper()
+	directlyAs(t).PanicsWithValue(want, f, msgAndArgs...)
+}
+
+// PanicsWithError asserts that the code inside the specified PanicTestFunc
+// panics, and that the recovered panic value is an error whose message
+// equals the specified string.
+func PanicsWithError(t testing.TB, want string, f PanicTestFunc, msgAndArgs ...interface{}) {
+	t.Helper()
+	directlyAs(t).PanicsWithError(want, f, msgAndArgs...)
+}
+
+// PanicsMatching asserts that the code inside the specified PanicTestFunc
+// panics, and that the message of the recovered panic value matches the
+// specified regexp.
+func PanicsMatching(t testing.TB, rx interface{}, f PanicTestFunc, msgAndArgs ...interface{}) {
+	t.Helper()
+	directlyAs(t).PanicsMatching(rx, f, msgAndArgs...)
+}
+
 // NotPanics asserts that the code inside the specified PanicTestFunc does not panic.
 func NotPanics(t testing.TB, f PanicTestFunc, msgAndArgs ...interface{}) {
 	t.Helper()
@@ -87,6 +253,16 @@ func Eventually(t testing.TB, condition func(ctx context.Context) bool,
 	directlyAs(t).Eventually(condition, waitFor, checkInterval, msgAndArgs...)
 }
 
+// EventuallyWithT asserts that given condition will be met in waitFor time,
+// periodically checking target function each tick. The condition is met
+// if no check of the As object passed to it fails, otherwise failures of
+// the last attempt are printed.
+func EventuallyWithT(t testing.TB, condition func(a *As),
+	waitFor, checkInterval time.Duration, msgAndArgs ...interface{}) {
+	t.Helper()
+	directlyAs(t).EventuallyWithT(condition, waitFor, checkInterval, msgAndArgs...)
+}
+
 // Never asserts that the given condition doesn't satisfy in waitFor time,
 // periodically checking the target function each tick.
 func Never(t testing.TB, condition func(ctx context.Context) bool,
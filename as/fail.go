@@ -10,6 +10,11 @@ import (
 
 func (c *As) fail(title string, details []string, msgAndArgs ...interface{}) {
 	c.Helper()
+	if c.soft != nil {
+		// As in soft mode may be shared by goroutines, e.g. Collect.
+		c.soft.mu.Lock()
+		defer c.soft.mu.Unlock()
+	}
 
 	// write info
 	c.info.WriteByte('\n')
@@ -17,8 +22,19 @@ func (c *As) fail(title string, details []string, msgAndArgs ...interface{}) {
 	c.writeError(title, details)
 	c.info.WriteString("message:\n")
 	c.writeMessage(msg(msgAndArgs...))
+	if len(c.caseInfo) > 0 {
+		c.info.WriteString("case:\n")
+		for _, line := range c.caseInfo {
+			fmt.Fprintf(&c.info, "%s%s\n", prefix, line)
+		}
+	}
 	c.info.WriteString("stack:\n")
 	c.writeStack()
+	if c.soft != nil {
+		c.soft.failures = append(c.soft.failures, c.info.String())
+		c.info.Reset()
+		return
+	}
 	c.TB.Error(c.info.String())
 	c.info.Reset()
 
@@ -60,22 +76,43 @@ func (c *As) writeStack() {
 	runtime.Callers(c.skipCallers, pc)
 	frames := runtime.CallersFrames(pc)
 	thisPackage := reflect.TypeOf(As{}).PkgPath() + "."
+	// In soft mode, checks may be called by as internal calls, e.g. the
+	// condition of EventuallyWithT, so they are skipped until reaching the
+	// caller.
+	leading := c.soft != nil
+	// Stdlib reflection calls are held until the next frame, and skipped
+	// if they are called by Suite to run test methods.
+	var held []runtime.Frame
 	for {
 		frame, more := frames.Next()
 		if strings.HasPrefix(frame.Function, "testing.") {
 			// Stop before getting back to stdlib test runner calls.
 			break
 		}
+		if strings.HasPrefix(frame.Function, "reflect.") && more {
+			held = append(held, frame)
+			continue
+		}
+		if !strings.HasPrefix(frame.Function, thisPackage+"Suite.") {
+			for _, f := range held {
+				fmt.Fprintf(&c.info, "%s%s:%d\n", prefix, f.File, f.Line)
+			}
+		}
+		held = nil
 		if fname := strings.TrimPrefix(
 			frame.Function, thisPackage,
 		); fname != frame.Function {
-			if ast.IsExported(fname) {
+			if c.soft != nil {
+				fname = methodName(fname)
+			}
+			if leading || ast.IsExported(fname) {
 				// Continue without printing frames for as exported API.
 				continue
 			}
 			// Stop when entering as internal calls.
 			break
 		}
+		leading = false
 		fmt.Fprintf(&c.info, "%s%s:%d\n", prefix, frame.File, frame.Line)
 		if !more {
 			// There are no more callers.
@@ -84,5 +121,16 @@ func (c *As) writeStack() {
 	}
 }
 
+// methodName trims the receiver of a method name, e.g. "(*As).Equal"
+// becomes "Equal".
+func methodName(fname string) string {
+	if strings.HasPrefix(fname, "(") {
+		if i := strings.Index(fname, ")."); i >= 0 {
+			return fname[i+2:]
+		}
+	}
+	return fname
+}
+
 // prefix is the string used to indent blocks of output.
 const prefix = "  "
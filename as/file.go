@@ -0,0 +1,260 @@
+package as
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+// FileExists checks that the specified path exists and is not a directory.
+func (c *As) FileExists(path string, msgAndArgs ...interface{}) {
+	c.Helper()
+	info, err := os.Lstat(path)
+	if err != nil {
+		c.fail("file should exist", []string{
+			"path: " + path,
+			fmt.Sprintf("error: %s", err),
+		}, msgAndArgs...)
+		return
+	}
+	if info.IsDir() {
+		c.fail("should be a file", []string{
+			"path: " + path,
+			fmt.Sprintf("mode: %s", info.Mode()),
+		}, msgAndArgs...)
+	}
+}
+
+// DirExists checks that the specified path exists and is a directory.
+func (c *As) DirExists(path string, msgAndArgs ...interface{}) {
+	c.Helper()
+	info, err := os.Lstat(path)
+	if err != nil {
+		c.fail("directory should exist", []string{
+			"path: " + path,
+			fmt.Sprintf("error: %s", err),
+		}, msgAndArgs...)
+		return
+	}
+	if !info.IsDir() {
+		c.fail("should be a directory", []string{
+			"path: " + path,
+			fmt.Sprintf("mode: %s", info.Mode()),
+		}, msgAndArgs...)
+	}
+}
+
+// NoFileExists checks that nothing exists at the specified path.
+func (c *As) NoFileExists(path string, msgAndArgs ...interface{}) {
+	c.Helper()
+	info, err := os.Lstat(path)
+	if err == nil {
+		c.fail("should not exist", []string{
+			"path: " + path,
+			fmt.Sprintf("mode: %s", info.Mode()),
+		}, msgAndArgs...)
+		return
+	}
+	if !os.IsNotExist(err) {
+		c.fail("failed checking path", []string{
+			"path: " + path,
+			fmt.Sprintf("error: %s", err),
+		}, msgAndArgs...)
+	}
+}
+
+// FileMode checks that the permission bits of the specified path equal to
+// perm, e.g. 0644.
+func (c *As) FileMode(path string, perm os.FileMode, msgAndArgs ...interface{}) {
+	c.Helper()
+	info, err := os.Lstat(path)
+	if err != nil {
+		c.fail("failed checking path", []string{
+			"path: " + path,
+			fmt.Sprintf("error: %s", err),
+		}, msgAndArgs...)
+		return
+	}
+	if info.Mode().Perm() != perm.Perm() {
+		c.fail("file mode should equal", []string{
+			"path: " + path,
+			fmt.Sprintf("want: %s (%#o)", perm.Perm(), uint32(perm.Perm())),
+			fmt.Sprintf("got: %s (%#o)", info.Mode().Perm(), uint32(info.Mode().Perm())),
+		}, msgAndArgs...)
+	}
+}
+
+// FileContent checks that content of the specified file equals to want.
+func (c *As) FileContent(path string, want string, msgAndArgs ...interface{}) {
+	c.Helper()
+	got, err := os.ReadFile(path)
+	if err != nil {
+		c.fail("failed reading file", []string{
+			"path: " + path,
+			fmt.Sprintf("error: %s", err),
+		}, msgAndArgs...)
+		return
+	}
+	if string(got) != want {
+		c.fail("file content should equal", append([]string{
+			"path: " + path,
+			"diff (-want +got):",
+		}, lineDiff(want, string(got))...), msgAndArgs...)
+	}
+}
+
+// TreeEqual checks that two directory trees have the same names, modes and
+// contents of files, reporting differences per relative path.
+func (c *As) TreeEqual(dirA, dirB string, msgAndArgs ...interface{}) {
+	c.Helper()
+	a, err := readTree(dirA)
+	if err != nil {
+		c.fail("failed reading directory tree", []string{
+			"path: " + dirA,
+			fmt.Sprintf("error: %s", err),
+		}, msgAndArgs...)
+		return
+	}
+	b, err := readTree(dirB)
+	if err != nil {
+		c.fail("failed reading directory tree", []string{
+			"path: " + dirB,
+			fmt.Sprintf("error: %s", err),
+		}, msgAndArgs...)
+		return
+	}
+
+	paths := make([]string, 0, len(a)+len(b))
+	for p := range a {
+		paths = append(paths, p)
+	}
+	for p := range b {
+		if _, ok := a[p]; !ok {
+			paths = append(paths, p)
+		}
+	}
+	sort.Strings(paths)
+
+	var lines []string
+	omitted := 0
+	report := func(format string, args ...interface{}) {
+		if len(lines) >= maxDiffLines {
+			omitted++
+			return
+		}
+		lines = append(lines, prefix+fmt.Sprintf(format, args...))
+	}
+	for _, p := range paths {
+		ea, oka := a[p]
+		eb, okb := b[p]
+		switch {
+		case !okb:
+			report("%s: only in %s", p, dirA)
+		case !oka:
+			report("%s: only in %s", p, dirB)
+		case ea.mode != eb.mode:
+			report("%s: mode %s != %s", p, ea.mode, eb.mode)
+		case ea.link != eb.link:
+			report("%s: link %q != %q", p, ea.link, eb.link)
+		case !bytes.Equal(ea.content, eb.content):
+			report("%s: content differs (%d bytes != %d bytes)", p,
+				len(ea.content), len(eb.content))
+		}
+	}
+	if len(lines) == 0 {
+		return
+	}
+	if omitted > 0 {
+		lines = append(lines, fmt.Sprintf("%s... (%d more differences)", prefix, omitted))
+	}
+	c.fail("directory trees should equal", append([]string{
+		"a: " + dirA,
+		"b: " + dirB,
+		"diff:",
+	}, lines...), msgAndArgs...)
+}
+
+// FileExists asserts that the specified path exists and is not a directory.
+func FileExists(t testing.TB, path string, msgAndArgs ...interface{}) {
+	t.Helper()
+	directlyAs(t).FileExists(path, msgAndArgs...)
+}
+
+// DirExists asserts that the specified path exists and is a directory.
+func DirExists(t testing.TB, path string, msgAndArgs ...interface{}) {
+	t.Helper()
+	directlyAs(t).DirExists(path, msgAndArgs...)
+}
+
+// NoFileExists asserts that nothing exists at the specified path.
+func NoFileExists(t testing.TB, path string, msgAndArgs ...interface{}) {
+	t.Helper()
+	directlyAs(t).NoFileExists(path, msgAndArgs...)
+}
+
+// FileMode asserts that the permission bits of the specified path equal to
+// perm, e.g. 0644.
+func FileMode(t testing.TB, path string, perm os.FileMode, msgAndArgs ...interface{}) {
+	t.Helper()
+	directlyAs(t).FileMode(path, perm, msgAndArgs...)
+}
+
+// FileContent asserts that content of the specified file equals to want.
+func FileContent(t testing.TB, path string, want string, msgAndArgs ...interface{}) {
+	t.Helper()
+	directlyAs(t).FileContent(path, want, msgAndArgs...)
+}
+
+// TreeEqual asserts that two directory trees have the same names, modes and
+// contents of files, reporting differences per relative path.
+func TreeEqual(t testing.TB, dirA, dirB string, msgAndArgs ...interface{}) {
+	t.Helper()
+	directlyAs(t).TreeEqual(dirA, dirB, msgAndArgs...)
+}
+
+// treeEntry is a file or directory in a tree read by readTree.
+type treeEntry struct {
+	mode    os.FileMode
+	link    string
+	content []byte
+}
+
+// readTree reads all entries under root, keyed by slash separated paths
+// relative to root. Symbolic links are not followed.
+func readTree(root string) (map[string]treeEntry, error) {
+	entries := make(map[string]treeEntry)
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		e := treeEntry{mode: info.Mode()}
+		switch {
+		case info.Mode()&os.ModeSymlink != 0:
+			if e.link, err = os.Readlink(path); err != nil {
+				return err
+			}
+		case info.Mode().IsRegular():
+			if e.content, err = os.ReadFile(path); err != nil {
+				return err
+			}
+		}
+		entries[filepath.ToSlash(rel)] = e
+		return nil
+	})
+	return entries, err
+}
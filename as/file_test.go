@@ -0,0 +1,72 @@
+//go:build !windows
+// +build !windows
+
+package as_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/elvinchan/util-collects/as"
+)
+
+func TestFile(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "a.txt")
+	as.NoError(t, os.WriteFile(file, []byte("hello\nworld\n"), 0600))
+	as.NoError(t, os.Chmod(file, 0640))
+
+	assertOk(t, "FileExists", func(t testing.TB) {
+		as.FileExists(t, file)
+	})
+	assertOk(t, "DirExists", func(t testing.TB) {
+		as.DirExists(t, dir)
+	})
+	assertOk(t, "NoFileExists", func(t testing.TB) {
+		as.NoFileExists(t, filepath.Join(dir, "b.txt"))
+	})
+	assertOk(t, "FileMode", func(t testing.TB) {
+		as.FileMode(t, file, 0640)
+	})
+	assertOk(t, "FileContent", func(t testing.TB) {
+		as.FileContent(t, file, "hello\nworld\n")
+	})
+
+	assertFail(t, "FileExists", func(t testing.TB) {
+		as.FileExists(t, dir)
+	})
+	assertFail(t, "DirExists", func(t testing.TB) {
+		as.DirExists(t, file)
+	})
+	assertFail(t, "NoFileExists", func(t testing.TB) {
+		as.NoFileExists(t, file)
+	})
+	assertFailWith(t, "FileMode", func(t testing.TB) {
+		as.FileMode(t, file, 0644)
+	}, "want: -rw-r--r-- (0644)", "got: -rw-r----- (0640)")
+	assertFailWith(t, "FileContent", func(t testing.TB) {
+		as.FileContent(t, file, "hello\nthere\n")
+	}, "-2: there", "+2: world")
+}
+
+func TestTreeEqual(t *testing.T) {
+	a, b := t.TempDir(), t.TempDir()
+	for _, dir := range []string{a, b} {
+		as.NoError(t, os.MkdirAll(filepath.Join(dir, "sub"), 0755))
+		as.NoError(t, os.WriteFile(filepath.Join(dir, "sub", "x"), []byte("x"), 0644))
+		as.NoError(t, os.Symlink("sub/x", filepath.Join(dir, "link")))
+	}
+	assertOk(t, "Equal", func(t testing.TB) {
+		as.TreeEqual(t, a, b)
+	})
+
+	as.NoError(t, os.WriteFile(filepath.Join(a, "only_a"), nil, 0644))
+	as.NoError(t, os.WriteFile(filepath.Join(b, "sub", "x"), []byte("xy"), 0644))
+	as.NoError(t, os.Chmod(filepath.Join(b, "sub"), 0700))
+	assertFailWith(t, "NotEqual", func(t testing.TB) {
+		as.TreeEqual(t, a, b)
+	}, "only_a: only in "+a,
+		"sub: mode drwxr-xr-x != drwx------",
+		"sub/x: content differs (1 bytes != 2 bytes)")
+}
@@ -0,0 +1,111 @@
+//go:build go1.18
+// +build go1.18
+
+package as
+
+import "testing"
+
+// Eq asserts that two comparable values of the same type are equal.
+// Unlike Equal, mismatched types are reported at compile time.
+func Eq[T comparable](t testing.TB, got, want T, msgAndArgs ...interface{}) {
+	t.Helper()
+	if got == want {
+		return
+	}
+	directlyAs(t).fail("should equal", equalDetails(want, got), msgAndArgs...)
+}
+
+// NotEq asserts that two comparable values of the same type are not equal.
+func NotEq[T comparable](t testing.TB, got, want T, msgAndArgs ...interface{}) {
+	t.Helper()
+	if got != want {
+		return
+	}
+	directlyAs(t).fail("should not equal", []string{
+		"got: " + formatShort(got),
+	}, msgAndArgs...)
+}
+
+// EqSlice asserts that two slices have the same length and equal elements
+// in the same order. A nil slice equals to an empty slice.
+func EqSlice[T comparable](t testing.TB, got, want []T, msgAndArgs ...interface{}) {
+	t.Helper()
+	if slicesEqual(got, want) {
+		return
+	}
+	directlyAs(t).fail("should equal", equalDetails(want, got), msgAndArgs...)
+}
+
+// EqMap asserts that two maps have the same keys with equal values.
+// A nil map equals to an empty map.
+func EqMap[K, V comparable](t testing.TB, got, want map[K]V, msgAndArgs ...interface{}) {
+	t.Helper()
+	if mapsEqual(got, want) {
+		return
+	}
+	directlyAs(t).fail("should equal", equalDetails(want, got), msgAndArgs...)
+}
+
+// ElementsMatch asserts that two slices contain the same elements, ignoring
+// the order of them. Duplicated elements must appear the same times. Use
+// ElementsMatchAny for slices of non-comparable types or arrays.
+func ElementsMatch[T comparable](t testing.TB, got, want []T, msgAndArgs ...interface{}) {
+	t.Helper()
+	counts := make(map[T]int, len(want))
+	for _, v := range want {
+		counts[v]++
+	}
+	var extra []T
+	for _, v := range got {
+		if counts[v] > 0 {
+			counts[v]--
+		} else {
+			extra = append(extra, v)
+		}
+	}
+	var missing []T
+	for _, v := range want {
+		if counts[v] > 0 {
+			counts[v]--
+			missing = append(missing, v)
+		}
+	}
+	if len(extra) == 0 && len(missing) == 0 {
+		return
+	}
+	details := []string{
+		"want: " + formatShort(want),
+		"got: " + formatShort(got),
+	}
+	if len(missing) > 0 {
+		details = append(details, "missing: "+formatShort(missing))
+	}
+	if len(extra) > 0 {
+		details = append(details, "extra: "+formatShort(extra))
+	}
+	directlyAs(t).fail("elements should match", details, msgAndArgs...)
+}
+
+func slicesEqual[T comparable](a, b []T) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func mapsEqual[K, V comparable](a, b map[K]V) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, av := range a {
+		if bv, ok := b[k]; !ok || av != bv {
+			return false
+		}
+	}
+	return true
+}
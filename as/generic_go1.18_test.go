@@ -0,0 +1,46 @@
+//go:build go1.18
+// +build go1.18
+
+package as_test
+
+import (
+	"testing"
+
+	"github.com/elvinchan/util-collects/as"
+)
+
+func TestGeneric(t *testing.T) {
+	assertOk(t, "Eq", func(t testing.TB) {
+		as.Eq(t, int64(1), 1)
+		as.Eq(t, struct{ A string }{"a"}, struct{ A string }{"a"})
+	})
+	assertOk(t, "NotEq", func(t testing.TB) {
+		as.NotEq(t, "a", "b")
+	})
+	assertOk(t, "EqSlice", func(t testing.TB) {
+		as.EqSlice(t, []int{1, 2}, []int{1, 2})
+		as.EqSlice(t, nil, []int{})
+	})
+	assertOk(t, "EqMap", func(t testing.TB) {
+		as.EqMap(t, map[string]int{"a": 1}, map[string]int{"a": 1})
+	})
+	assertOk(t, "ElementsMatch", func(t testing.TB) {
+		as.ElementsMatch(t, []int{1, 2, 2, 3}, []int{2, 3, 1, 2})
+	})
+
+	assertFailWith(t, "Eq", func(t testing.TB) {
+		as.Eq(t, struct{ A string }{"a"}, struct{ A string }{"b"})
+	}, `.A: "b" != "a"`)
+	assertFail(t, "NotEq", func(t testing.TB) {
+		as.NotEq(t, 1, 1)
+	})
+	assertFailWith(t, "EqSlice", func(t testing.TB) {
+		as.EqSlice(t, []int{1, 2}, []int{1, 3})
+	}, "[1]: 3 != 2")
+	assertFailWith(t, "EqMap", func(t testing.TB) {
+		as.EqMap(t, map[string]int{"a": 1}, map[string]int{"b": 1})
+	}, `["a"]: <missing> != 1`, `["b"]: 1 != <missing>`)
+	assertFailWith(t, "ElementsMatch", func(t testing.TB) {
+		as.ElementsMatch(t, []int{1, 2, 2}, []int{2, 3, 1})
+	}, "missing: [3]", "extra: [2]")
+}
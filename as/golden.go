@@ -0,0 +1,242 @@
+package as
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// GoldenOption specified option for golden file checks.
+type GoldenOption func(*golden)
+
+type golden struct {
+	dir  string
+	json bool
+}
+
+// GoldenWithDir specified the directory of golden files, defaults to
+// "testdata".
+func GoldenWithDir(dir string) GoldenOption {
+	return func(g *golden) {
+		g.dir = dir
+	}
+}
+
+// GoldenWithJSON specified normalizing content as a stream of JSON values
+// before comparing, so that key order and indentation are ignored.
+func GoldenWithJSON() GoldenOption {
+	return func(g *golden) {
+		g.json = true
+	}
+}
+
+// Golden checks that got equals to content of the golden file
+// `testdata/<TestName>/<name>.golden`. The golden file is rewritten with got
+// when test runs with UPDATE_GOLDEN environment variable, or `-update` flag
+// if it is defined by the test package, e.g.
+//
+//	var _ = flag.Bool("update", false, "update golden files")
+//
+// GoldenOption values may be passed before msgAndArgs, and it fails if any
+// is passed after.
+func (c *As) Golden(name string, got []byte, msgAndArgs ...interface{}) {
+	c.Helper()
+	g := &golden{
+		dir: "testdata",
+	}
+	for len(msgAndArgs) > 0 {
+		opt, ok := msgAndArgs[0].(GoldenOption)
+		if !ok {
+			break
+		}
+		opt(g)
+		msgAndArgs = msgAndArgs[1:]
+	}
+	for _, arg := range msgAndArgs {
+		if _, ok := arg.(GoldenOption); ok {
+			c.fail("invalid operation", []string{
+				"GoldenOption must be passed before msgAndArgs",
+			}, msgAndArgs...)
+			return
+		}
+	}
+	path := filepath.Join(g.dir, filepath.FromSlash(c.Name()), name+".golden")
+
+	if g.json {
+		var err error
+		if got, err = normalizeJSON(got); err != nil {
+			c.fail("invalid operation", []string{
+				fmt.Sprintf("got is not valid JSON (%s)", err),
+			}, msgAndArgs...)
+			return
+		}
+	}
+
+	if updatingGolden() {
+		err := os.MkdirAll(filepath.Dir(path), 0755)
+		if err == nil {
+			err = os.WriteFile(path, got, 0644)
+		}
+		if err != nil {
+			c.fail("failed updating golden file", []string{
+				fmt.Sprintf("path: %s", path),
+				fmt.Sprintf("error: %s", err),
+			}, msgAndArgs...)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		details := []string{
+			fmt.Sprintf("path: %s", path),
+			fmt.Sprintf("error: %s", err),
+		}
+		if errors.Is(err, os.ErrNotExist) {
+			details = append(details, "run test with UPDATE_GOLDEN=1 or -update flag to create it")
+		}
+		c.fail("failed reading golden file", details, msgAndArgs...)
+		return
+	}
+	if g.json {
+		if want, err = normalizeJSON(want); err != nil {
+			c.fail("invalid operation", []string{
+				fmt.Sprintf("golden file %s is not valid JSON (%s)", path, err),
+			}, msgAndArgs...)
+			return
+		}
+	}
+	if bytes.Equal(want, got) {
+		return
+	}
+	c.fail("should equal golden file", append([]string{
+		fmt.Sprintf("path: %s", path),
+		"diff (-want +got):",
+	}, lineDiff(string(want), string(got))...), msgAndArgs...)
+}
+
+// Golden asserts that got equals to content of the golden file
+// `testdata/<TestName>/<name>.golden`. The golden file is rewritten with got
+// when test runs with UPDATE_GOLDEN environment variable, or `-update` flag
+// if it is defined by the test package. GoldenOption values may be passed
+// before msgAndArgs, and it fails if any is passed after.
+func Golden(t testing.TB, name string, got []byte, msgAndArgs ...interface{}) {
+	t.Helper()
+	directlyAs(t).Golden(name, got, msgAndArgs...)
+}
+
+// updatingGolden reports whether golden files should be rewritten. The
+// `-update` flag is looked up lazily rather than defined here, so that it
+// does not conflict with the one defined by packages importing as.
+func updatingGolden() bool {
+	if os.Getenv("UPDATE_GOLDEN") != "" {
+		return true
+	}
+	f := flag.Lookup("update")
+	if f == nil {
+		return false
+	}
+	getter, ok := f.Value.(flag.Getter)
+	if !ok {
+		return false
+	}
+	update, _ := getter.Get().(bool)
+	return update
+}
+
+// normalizeJSON re-encodes a stream of JSON values with sorted keys and
+// fixed indentation, one value after another.
+func normalizeJSON(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	for {
+		var v interface{}
+		if err := dec.Decode(&v); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if err := enc.Encode(v); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// lineDiff returns the changed lines between want and got, prefixed by
+// "-" for lines only in want and "+" for lines only in got, with the line
+// number of each side.
+func lineDiff(want, got string) []string {
+	a := strings.Split(want, "\n")
+	b := strings.Split(got, "\n")
+
+	// Fallback to compare line by line if the table of LCS is too large.
+	if len(a)*len(b) > 1<<22 {
+		var lines []string
+		for i := 0; i < len(a) || i < len(b); i++ {
+			if i < len(a) && i < len(b) && a[i] == b[i] {
+				continue
+			}
+			if i < len(a) {
+				lines = appendDiffLine(lines, "-", i, a[i])
+			}
+			if i < len(b) {
+				lines = appendDiffLine(lines, "+", i, b[i])
+			}
+		}
+		return lines
+	}
+
+	// lcs[i][j] is the length of longest common subsequence of a[i:], b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	var lines []string
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			i++
+			j++
+		case j == len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			lines = appendDiffLine(lines, "-", i, a[i])
+			i++
+		default:
+			lines = appendDiffLine(lines, "+", j, b[j])
+			j++
+		}
+	}
+	return lines
+}
+
+func appendDiffLine(lines []string, sign string, i int, line string) []string {
+	if len(lines) == maxDiffLines {
+		return append(lines, "... (more differences)")
+	} else if len(lines) > maxDiffLines {
+		return lines
+	}
+	return append(lines, fmt.Sprintf("%s%s%d: %s", prefix, sign, i+1, truncate(line)))
+}
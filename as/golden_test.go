@@ -0,0 +1,68 @@
+package as_test
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/elvinchan/util-collects/as"
+)
+
+// update is defined by test package as the convention of golden files,
+// which must not conflict with as.
+var update = flag.Bool("update", false, "update golden files")
+
+func TestGolden(t *testing.T) {
+	as.Golden(t, "text", []byte("INFO | hello\nWARN | world\n"))
+	as.Golden(t, "json", []byte(`{"b":{"c":[true,null]},"a":1}`), as.GoldenWithJSON())
+
+	// failures are checked with golden files seeded in a temporary
+	// directory, which are not rewritten when updating
+	dir := t.TempDir()
+	as.NoError(t, os.MkdirAll(filepath.Join(dir, "TestGolden", "Mismatch"), 0755))
+	as.NoError(t, os.WriteFile(filepath.Join(dir, "TestGolden", "Mismatch", "text.golden"),
+		[]byte("INFO | hello\nWARN | world\n"), 0644))
+	assertFailWith(t, "Mismatch", func(t testing.TB) {
+		noUpdate(t)
+		as.Golden(t, "text", []byte("INFO | hello\nERROR | world\n"), as.GoldenWithDir(dir))
+	}, "-2: WARN | world", "+2: ERROR | world")
+	assertFailWith(t, "NotExist", func(t testing.TB) {
+		noUpdate(t)
+		as.Golden(t, "not_exist", nil, as.GoldenWithDir(dir), "output of %s", "hello")
+	}, "-update", "output of hello")
+	assertFailWith(t, "OptionAfterMessage", func(t testing.TB) {
+		noUpdate(t)
+		as.Golden(t, "text", nil, "message", as.GoldenWithDir(dir))
+	}, "invalid operation", "GoldenOption")
+
+	t.Run("Update", func(t *testing.T) {
+		dir := t.TempDir()
+		t.Setenv("UPDATE_GOLDEN", "1")
+		as.Golden(t, "json", []byte(`{"b":2,"a":1}`), as.GoldenWithDir(dir), as.GoldenWithJSON())
+
+		b, err := os.ReadFile(filepath.Join(dir, "TestGolden", "Update", "json.golden"))
+		as.NoError(t, err)
+		as.Equal(t, string(b), "{\n  \"a\": 1,\n  \"b\": 2\n}\n")
+	})
+
+	t.Run("UpdateFlag", func(t *testing.T) {
+		if !*update {
+			as.NoError(t, flag.Set("update", "true"))
+			defer flag.Set("update", "false")
+		}
+		dir := t.TempDir()
+		as.Golden(t, "text", []byte("hello\n"), as.GoldenWithDir(dir))
+		as.FileContent(t, filepath.Join(dir, "TestGolden", "UpdateFlag", "text.golden"), "hello\n")
+	})
+}
+
+// noUpdate disables updating golden files in t, for the checks expected to
+// fail.
+func noUpdate(t testing.TB) {
+	t.Setenv("UPDATE_GOLDEN", "")
+	if *update {
+		*update = false
+		t.Cleanup(func() { *update = true })
+	}
+}
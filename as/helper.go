@@ -3,8 +3,15 @@ package as
 import (
 	"bytes"
 	"errors"
+	"fmt"
+	"math"
+	"math/big"
 	"reflect"
 	"regexp"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
 )
 
 func validateEqualArgs(want, got interface{}) error {
@@ -45,18 +52,296 @@ func objectsAreEqual(want, got interface{}) bool {
 	return bytes.Equal(exp, act)
 }
 
+// getLen tries to get length of object, returns false if impossible.
+func getLen(object interface{}) (l int, ok bool) {
+	v := reflect.ValueOf(object)
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map, reflect.Chan:
+		return v.Len(), true
+	}
+	return 0, false
+}
+
+func isEmpty(object interface{}) bool {
+	if object == nil {
+		return true
+	}
+	v := reflect.ValueOf(object)
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map, reflect.Chan:
+		return v.Len() == 0
+	case reflect.Ptr:
+		if v.IsNil() {
+			return true
+		}
+		return isEmpty(v.Elem().Interface())
+	}
+	return v.IsZero()
+}
+
+func isList(object interface{}) bool {
+	if object == nil {
+		return false
+	}
+	k := reflect.TypeOf(object).Kind()
+	return k == reflect.Slice || k == reflect.Array
+}
+
+// containsElement tries to loop over s to check if it contains element,
+// returns false for ok if impossible.
+func containsElement(s, element interface{}) (ok, found bool) {
+	v := reflect.ValueOf(s)
+	switch v.Kind() {
+	case reflect.String:
+		e, isString := element.(string)
+		if !isString {
+			return false, false
+		}
+		return true, strings.Contains(v.String(), e)
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			if objectsAreEqual(iter.Key().Interface(), element) {
+				return true, true
+			}
+		}
+		return true, false
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if objectsAreEqual(v.Index(i).Interface(), element) {
+				return true, true
+			}
+		}
+		return true, false
+	}
+	return false, false
+}
+
+// diffLists returns elements appear in got but not in want as extra, and
+// elements appear in want but not in got as missing.
+func diffLists(got, want interface{}) (extra, missing []interface{}) {
+	gv, wv := reflect.ValueOf(got), reflect.ValueOf(want)
+	matched := make([]bool, wv.Len())
+	for i := 0; i < gv.Len(); i++ {
+		e := gv.Index(i).Interface()
+		found := false
+		for j := 0; j < wv.Len(); j++ {
+			if !matched[j] && objectsAreEqual(wv.Index(j).Interface(), e) {
+				matched[j] = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			extra = append(extra, e)
+		}
+	}
+	for j := 0; j < wv.Len(); j++ {
+		if !matched[j] {
+			missing = append(missing, wv.Index(j).Interface())
+		}
+	}
+	return
+}
+
+// missingElements returns elements of subset which are not in list, returns
+// false for ok if list and subset are not both list or both map.
+func missingElements(list, subset interface{}) (missing []interface{}, ok bool) {
+	if subset == nil {
+		return nil, list != nil
+	}
+	lv, sv := reflect.ValueOf(list), reflect.ValueOf(subset)
+	if lv.Kind() == reflect.Map && sv.Kind() == reflect.Map {
+		iter := sv.MapRange()
+		for iter.Next() {
+			// keys of other types are missing, instead of panic in MapIndex
+			var v reflect.Value
+			k := iter.Key()
+			if k.Kind() == reflect.Interface && !k.IsNil() {
+				k = k.Elem()
+			}
+			if k.Type().AssignableTo(lv.Type().Key()) {
+				v = lv.MapIndex(k)
+			}
+			if !v.IsValid() || !objectsAreEqual(iter.Value().Interface(), v.Interface()) {
+				missing = append(missing, fmt.Sprintf("%v:%v", iter.Key(), iter.Value()))
+			}
+		}
+		return missing, true
+	}
+	if !isList(list) || !isList(subset) {
+		return nil, false
+	}
+	for i := 0; i < sv.Len(); i++ {
+		e := sv.Index(i).Interface()
+		if _, found := containsElement(list, e); !found {
+			missing = append(missing, e)
+		}
+	}
+	return missing, true
+}
+
+// toFloat converts numbers of any integer or float kinds to float64.
+func toFloat(x interface{}) (float64, bool) {
+	v := reflect.ValueOf(x)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+	return 0, false
+}
+
+// toBigInt converts numbers of any integer kinds to big.Int, so that they
+// are compared exactly even beyond the precision of float64.
+func toBigInt(x interface{}) (*big.Int, bool) {
+	v := reflect.ValueOf(x)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return big.NewInt(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr:
+		return new(big.Int).SetUint64(v.Uint()), true
+	}
+	return nil, false
+}
+
+// formatLike formats f as the same type of like, so that a difference of
+// time.Duration is printed as a duration rather than a float.
+func formatLike(like interface{}, f float64) string {
+	if _, ok := like.(time.Duration); ok {
+		return time.Duration(f).String()
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// compareOrdered compares two values of the same ordered kind, returns -1,
+// 0 or +1 for e1 less than, equal to or greater than e2. ok is false if
+// the values are not comparable.
+func compareOrdered(e1, e2 interface{}) (r int, ok bool) {
+	if e1 == nil || e2 == nil {
+		return 0, false
+	}
+	v1, v2 := reflect.ValueOf(e1), reflect.ValueOf(e2)
+	if v1.Type() != v2.Type() {
+		return 0, false
+	}
+	if t1, ok := e1.(time.Time); ok {
+		t2 := e2.(time.Time)
+		return sign(t1.After(t2), t1.Before(t2)), true
+	}
+	switch v1.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return sign(v1.Int() > v2.Int(), v1.Int() < v2.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr:
+		return sign(v1.Uint() > v2.Uint(), v1.Uint() < v2.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		if math.IsNaN(v1.Float()) || math.IsNaN(v2.Float()) {
+			return 0, false
+		}
+		return sign(v1.Float() > v2.Float(), v1.Float() < v2.Float()), true
+	case reflect.String:
+		return strings.Compare(v1.String(), v2.String()), true
+	}
+	return 0, false
+}
+
+func sign(greater, less bool) int {
+	if greater {
+		return 1
+	}
+	if less {
+		return -1
+	}
+	return 0
+}
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+func validateErrorAsTarget(target interface{}) error {
+	if target == nil {
+		return errors.New("target cannot be nil")
+	}
+	val := reflect.ValueOf(target)
+	typ := val.Type()
+	if typ.Kind() != reflect.Ptr || val.IsNil() {
+		return errors.New("target must be a non-nil pointer")
+	}
+	if e := typ.Elem(); e.Kind() != reflect.Interface && !e.Implements(errorType) {
+		return errors.New("*target must be interface or implement error")
+	}
+	return nil
+}
+
+// errorChain returns one line for each error in the chain of err, indented
+// by the depth of it. Errors wrapping multiple errors, via either
+// `Unwrap() []error` or `WrappedErrors() []error`, are expanded as branches.
+func errorChain(err error) []string {
+	var lines []string
+	var walk func(err error, depth int)
+	walk = func(err error, depth int) {
+		if len(lines) >= maxDiffLines {
+			return
+		}
+		lines = append(lines, strings.Repeat(prefix, depth)+formatError(err))
+		switch x := err.(type) {
+		case interface{ Unwrap() []error }:
+			for _, e := range x.Unwrap() {
+				walk(e, depth+1)
+			}
+		case interface{ WrappedErrors() []error }:
+			for _, e := range x.WrappedErrors() {
+				walk(e, depth+1)
+			}
+		default:
+			if e := errors.Unwrap(err); e != nil {
+				walk(e, depth+1)
+			}
+		}
+	}
+	walk(err, 1)
+	return lines
+}
+
+// stackDetails returns the lines of stack as details, indented under a
+// "panic stack:" title.
+func stackDetails(stack string) []string {
+	lines := strings.Split(strings.TrimSpace(stack), "\n")
+	details := make([]string, 0, len(lines)+1)
+	details = append(details, "panic stack:")
+	for _, line := range lines {
+		details = append(details, prefix+line)
+	}
+	return details
+}
+
+func formatError(err error) string {
+	if err == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("%T: %s", err, truncate(strconv.Quote(err.Error())))
+}
+
 // PanicTestFunc defines a func that should be passed to the as.Panics and
 // as.NotPanics methods, and represents a simple func that takes no arguments,
 // and returns nothing.
 type PanicTestFunc func()
 
 // didPanic returns true if the function passed to it panics. Otherwise, it
-// returns false.
-func didPanic(f PanicTestFunc) (didPanic bool, message interface{}) {
+// returns false. The stack is where the function panics.
+func didPanic(f PanicTestFunc) (didPanic bool, message interface{}, stack string) {
 	didPanic = true
 
 	defer func() {
 		message = recover()
+		if didPanic {
+			stack = string(debug.Stack())
+		}
 	}()
 
 	// call the target function
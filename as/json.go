@@ -0,0 +1,321 @@
+package as
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// JSONEq checks that two JSON strings are semantically equal, ignoring key
+// order, whitespace and number formatting.
+func (c *As) JSONEq(got, want string, msgAndArgs ...interface{}) {
+	c.Helper()
+	g, err := parseJSON(got)
+	if err != nil {
+		c.fail("invalid operation", []string{
+			fmt.Sprintf("got is not valid JSON (%s)", err),
+			"got: " + truncate(got),
+		}, msgAndArgs...)
+		return
+	}
+	w, err := parseJSON(want)
+	if err != nil {
+		c.fail("invalid operation", []string{
+			fmt.Sprintf("want is not valid JSON (%s)", err),
+			"want: " + truncate(want),
+		}, msgAndArgs...)
+		return
+	}
+	lines := jsonDiff(w, g)
+	if len(lines) == 0 {
+		return
+	}
+	details := []string{
+		"want: " + truncate(want),
+		"got: " + truncate(got),
+		"diff (want != got):",
+	}
+	for _, line := range lines {
+		details = append(details, prefix+line)
+	}
+	c.fail("JSON should equal", details, msgAndArgs...)
+}
+
+// JSONPath checks that the value at path of the JSON document equals to
+// want, which is compared in its JSON form. Path is a subset of JSONPath
+// syntax, e.g. `$.fields.error`, `$.items[0].name` or `$['a.b']`.
+func (c *As) JSONPath(doc, path string, want interface{}, msgAndArgs ...interface{}) {
+	c.Helper()
+	d, err := parseJSON(doc)
+	if err != nil {
+		c.fail("invalid operation", []string{
+			fmt.Sprintf("doc is not valid JSON (%s)", err),
+			"doc: " + truncate(doc),
+		}, msgAndArgs...)
+		return
+	}
+	steps, err := parseJSONPath(path)
+	if err != nil {
+		c.fail("invalid operation", []string{
+			fmt.Sprintf("invalid path %q (%s)", path, err),
+		}, msgAndArgs...)
+		return
+	}
+	b, err := json.Marshal(want)
+	if err != nil {
+		c.fail("invalid operation", []string{
+			fmt.Sprintf("want cannot be marshaled to JSON (%s)", err),
+		}, msgAndArgs...)
+		return
+	}
+	w, _ := parseJSON(string(b))
+
+	got, found, reached := lookupJSON(d, steps)
+	if !found {
+		c.fail("JSON path should exist", []string{
+			"path: " + path,
+			"reached: " + reached,
+			"doc: " + truncate(doc),
+		}, msgAndArgs...)
+		return
+	}
+	lines := jsonDiffAt(reached, w, got)
+	if len(lines) == 0 {
+		return
+	}
+	details := []string{
+		"path: " + path,
+		"diff (want != got):",
+	}
+	for _, line := range lines {
+		details = append(details, prefix+line)
+	}
+	c.fail("JSON path should equal", details, msgAndArgs...)
+}
+
+// JSONEq asserts that two JSON strings are semantically equal, ignoring key
+// order, whitespace and number formatting.
+func JSONEq(t testing.TB, got, want string, msgAndArgs ...interface{}) {
+	t.Helper()
+	directlyAs(t).JSONEq(got, want, msgAndArgs...)
+}
+
+// JSONPath asserts that the value at path of the JSON document equals to
+// want, which is compared in its JSON form. Path is a subset of JSONPath
+// syntax, e.g. `$.fields.error`, `$.items[0].name` or `$['a.b']`.
+func JSONPath(t testing.TB, doc, path string, want interface{}, msgAndArgs ...interface{}) {
+	t.Helper()
+	directlyAs(t).JSONPath(doc, path, want, msgAndArgs...)
+}
+
+func parseJSON(s string) (interface{}, error) {
+	dec := json.NewDecoder(strings.NewReader(s))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("unexpected data after top-level value")
+	}
+	return v, nil
+}
+
+// jsonDiff returns a line for each difference of two decoded JSON values,
+// annotated by JSONPath, e.g. `$.items[3].name: "a" != "b"`.
+func jsonDiff(want, got interface{}) []string {
+	return jsonDiffAt("$", want, got)
+}
+
+func jsonDiffAt(path string, want, got interface{}) []string {
+	var lines []string
+	omitted := 0
+	report := func(path string, want, got string) {
+		if len(lines) >= maxDiffLines {
+			omitted++
+			return
+		}
+		lines = append(lines, fmt.Sprintf("%s: %s != %s", path, want, got))
+	}
+	var walk func(path string, want, got interface{})
+	walk = func(path string, want, got interface{}) {
+		switch w := want.(type) {
+		case map[string]interface{}:
+			g, ok := got.(map[string]interface{})
+			if !ok {
+				report(path, formatJSON(want), formatJSON(got))
+				return
+			}
+			keys := make([]string, 0, len(w)+len(g))
+			for k := range w {
+				keys = append(keys, k)
+			}
+			for k := range g {
+				if _, ok := w[k]; !ok {
+					keys = append(keys, k)
+				}
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				p := jsonPathJoin(path, k)
+				wv, wok := w[k]
+				gv, gok := g[k]
+				switch {
+				case !wok:
+					report(p, "<missing>", formatJSON(gv))
+				case !gok:
+					report(p, formatJSON(wv), "<missing>")
+				default:
+					walk(p, wv, gv)
+				}
+			}
+		case []interface{}:
+			g, ok := got.([]interface{})
+			if !ok {
+				report(path, formatJSON(want), formatJSON(got))
+				return
+			}
+			for i := 0; i < len(w) || i < len(g); i++ {
+				p := fmt.Sprintf("%s[%d]", path, i)
+				switch {
+				case i >= len(w):
+					report(p, "<missing>", formatJSON(g[i]))
+				case i >= len(g):
+					report(p, formatJSON(w[i]), "<missing>")
+				default:
+					walk(p, w[i], g[i])
+				}
+			}
+		case json.Number:
+			g, ok := got.(json.Number)
+			if !ok || !jsonNumberEqual(w, g) {
+				report(path, formatJSON(want), formatJSON(got))
+			}
+		default:
+			if want != got {
+				report(path, formatJSON(want), formatJSON(got))
+			}
+		}
+	}
+	walk(path, want, got)
+	if omitted > 0 {
+		lines = append(lines, fmt.Sprintf("... (%d more differences)", omitted))
+	}
+	return lines
+}
+
+// jsonNumberEqual compares numbers exactly as rational numbers, so that
+// large integers or long decimals are not rounded like float64.
+func jsonNumberEqual(a, b json.Number) bool {
+	if a == b {
+		return true
+	}
+	ar, ok1 := new(big.Rat).SetString(string(a))
+	br, ok2 := new(big.Rat).SetString(string(b))
+	return ok1 && ok2 && ar.Cmp(br) == 0
+}
+
+func formatJSON(v interface{}) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return truncate(strings.TrimSuffix(buf.String(), "\n"))
+}
+
+// jsonPathJoin appends key to path, quoting it if it is not an identifier.
+func jsonPathJoin(path, key string) string {
+	if key != "" && strings.IndexFunc(key, func(r rune) bool {
+		return !(r == '_' || r == '-' || r >= '0' && r <= '9' ||
+			r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z')
+	}) < 0 {
+		return path + "." + key
+	}
+	return path + "[" + strconv.Quote(key) + "]"
+}
+
+// jsonStep is a step of JSONPath, either a key of object or an index of
+// array.
+type jsonStep struct {
+	key   string
+	index int
+	isKey bool
+}
+
+func parseJSONPath(path string) ([]jsonStep, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, errors.New("path should start with $")
+	}
+	var steps []jsonStep
+	s := path[1:]
+	for len(s) > 0 {
+		switch s[0] {
+		case '.':
+			s = s[1:]
+			end := strings.IndexAny(s, ".[")
+			if end < 0 {
+				end = len(s)
+			}
+			if end == 0 {
+				return nil, errors.New("empty key")
+			}
+			steps = append(steps, jsonStep{key: s[:end], isKey: true})
+			s = s[end:]
+		case '[':
+			end := strings.IndexByte(s, ']')
+			if end < 0 {
+				return nil, errors.New("unclosed bracket")
+			}
+			inner := s[1:end]
+			if len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') &&
+				inner[len(inner)-1] == inner[0] {
+				steps = append(steps, jsonStep{key: inner[1 : len(inner)-1], isKey: true})
+			} else {
+				i, err := strconv.Atoi(inner)
+				if err != nil || i < 0 {
+					return nil, fmt.Errorf("invalid index %q", inner)
+				}
+				steps = append(steps, jsonStep{index: i})
+			}
+			s = s[end+1:]
+		default:
+			return nil, fmt.Errorf("unexpected %q", s[0])
+		}
+	}
+	return steps, nil
+}
+
+// lookupJSON returns the value at steps of doc, and the path has been
+// reached, which is the whole path if found or where it stops if not.
+func lookupJSON(doc interface{}, steps []jsonStep) (v interface{}, found bool, reached string) {
+	v, reached = doc, "$"
+	for _, step := range steps {
+		if step.isKey {
+			m, ok := v.(map[string]interface{})
+			if !ok {
+				return nil, false, reached
+			}
+			if v, ok = m[step.key]; !ok {
+				return nil, false, reached
+			}
+			reached = jsonPathJoin(reached, step.key)
+		} else {
+			a, ok := v.([]interface{})
+			if !ok || step.index >= len(a) {
+				return nil, false, reached
+			}
+			v = a[step.index]
+			reached = fmt.Sprintf("%s[%d]", reached, step.index)
+		}
+	}
+	return v, true, reached
+}
@@ -0,0 +1,46 @@
+package as_test
+
+import (
+	"testing"
+
+	"github.com/elvinchan/util-collects/as"
+)
+
+func TestJSON(t *testing.T) {
+	doc := `{"level": "ERROR", "fields": {"error": "sth wrong", "retries": 3,
+		"tags": ["a", "b"], "a.b": null}}`
+	assertOk(t, "JSONEq", func(t testing.TB) {
+		as.JSONEq(t, `{"b": [1, 2.0], "a": {"c": null}}`, `{"a":{"c":null},"b":[1.0,2]}`)
+	})
+	assertOk(t, "JSONPath", func(t testing.TB) {
+		as.JSONPath(t, doc, "$.fields.error", "sth wrong")
+		as.JSONPath(t, doc, "$.fields.retries", 3)
+		as.JSONPath(t, doc, "$.fields.tags[1]", "b")
+		as.JSONPath(t, doc, "$.fields['a.b']", nil)
+		as.JSONPath(t, doc, `$["fields"].tags`, []string{"a", "b"})
+	})
+
+	assertFailWith(t, "JSONEq", func(t testing.TB) {
+		as.JSONEq(t, `{"items": [{"name": "a"}, {"name": "c"}]}`,
+			`{"items": [{"name": "a"}, {"name": "b", "id": 1}]}`)
+	}, `$.items[1].id: 1 != <missing>`, `$.items[1].name: "b" != "c"`)
+	assertFailWith(t, "JSONEqInvalid", func(t testing.TB) {
+		as.JSONEq(t, `{`, `{}`)
+	}, "invalid operation")
+	assertFailWith(t, "JSONEqTrailing", func(t testing.TB) {
+		as.JSONEq(t, `{} }`, `{}`)
+	}, "unexpected data after top-level value")
+	assertFailWith(t, "JSONEqLargeNumber", func(t testing.TB) {
+		as.JSONEq(t, `[12345678901234567890, 0.10000000000000000001]`,
+			`[12345678901234567891, 0.1]`)
+	}, "$[0]: 12345678901234567891 != 12345678901234567890", "$[1]: 0.1 != 0.10000000000000000001")
+	assertFailWith(t, "JSONPath", func(t testing.TB) {
+		as.JSONPath(t, doc, "$.fields", map[string]interface{}{"error": "sth else"})
+	}, `$.fields.error: "sth else" != "sth wrong"`, `$.fields.retries: <missing> != 3`)
+	assertFailWith(t, "JSONPathNotExist", func(t testing.TB) {
+		as.JSONPath(t, doc, "$.fields.tags[2]", "c")
+	}, "reached: $.fields.tags")
+	assertFailWith(t, "JSONPathInvalid", func(t testing.TB) {
+		as.JSONPath(t, doc, "fields", "c")
+	}, "invalid path")
+}
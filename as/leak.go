@@ -0,0 +1,181 @@
+package as
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// LeakOption specified option for goroutine leak checks.
+type LeakOption func(*leak)
+
+type leak struct {
+	grace      time.Duration
+	ignoreTops []string
+	ignores    []string
+}
+
+// defaultLeakIgnores are the top functions of known background goroutines
+// of runtime and testing, which should never be reported. Only the top
+// function is matched, since every test goroutine has testing.tRunner in
+// its stack, including the blocked ones which are leaked.
+var defaultLeakIgnores = []string{
+	"testing.(*T).Run",
+	"testing.(*T).Parallel",
+	"testing.tRunner.func1",
+	"testing.runTests",
+	"testing.(*M).Run",
+	"os/signal.signal_recv",
+	"os/signal.loop",
+	"runtime.ensureSigM",
+	"runtime.ReadTrace",
+}
+
+// LeakWithGrace specified how long to wait for goroutines to exit before
+// reporting them as leaked, defaults to 1 second.
+func LeakWithGrace(grace time.Duration) LeakOption {
+	return func(l *leak) {
+		l.grace = grace
+	}
+}
+
+// LeakWithIgnore specified goroutines to be ignored, which stacks contain
+// any of the specified strings, e.g. function names like "pkg.(*T).loop".
+func LeakWithIgnore(ignores ...string) LeakOption {
+	return func(l *leak) {
+		l.ignores = append(l.ignores, ignores...)
+	}
+}
+
+// NoGoroutineLeak snapshots the running goroutines, and returns a function
+// which checks that no more goroutines are running than the snapshot, after
+// waiting a grace period for them to exit. It is usually used with defer:
+//
+//	defer a.NoGoroutineLeak()()
+func (c *As) NoGoroutineLeak(opts ...LeakOption) func() {
+	l := &leak{
+		grace:      time.Second,
+		ignoreTops: defaultLeakIgnores,
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	before := make(map[string]bool)
+	for _, g := range goroutines() {
+		before[g.id] = true
+	}
+
+	return func() {
+		c.Helper()
+		var leaked []goroutine
+		deadline := time.Now().Add(l.grace)
+		for {
+			leaked = leaked[:0]
+			for _, g := range goroutines() {
+				if !before[g.id] && !g.topIn(l.ignoreTops) && !g.contains(l.ignores) {
+					leaked = append(leaked, g)
+				}
+			}
+			if len(leaked) == 0 || !time.Now().Before(deadline) {
+				break
+			}
+			time.Sleep(time.Millisecond * 10)
+		}
+		if len(leaked) == 0 {
+			return
+		}
+
+		details := []string{fmt.Sprintf("leaked: %d", len(leaked))}
+		for i, g := range leaked {
+			if i == maxLeakReported {
+				details = append(details, fmt.Sprintf("... (%d more goroutines)",
+					len(leaked)-maxLeakReported))
+				break
+			}
+			for _, line := range strings.Split(g.stack, "\n") {
+				details = append(details, prefix+line)
+			}
+		}
+		c.fail("found leaked goroutines", details)
+	}
+}
+
+// NoGoroutineLeak snapshots the running goroutines, and returns a function
+// which asserts that no more goroutines are running than the snapshot, after
+// waiting a grace period for them to exit. It is usually used with defer:
+//
+//	defer as.NoGoroutineLeak(t)()
+func NoGoroutineLeak(t testing.TB, opts ...LeakOption) func() {
+	t.Helper()
+	return directlyAs(t).NoGoroutineLeak(opts...)
+}
+
+// maxLeakReported is the max number of leaked goroutines reported.
+const maxLeakReported = 10
+
+type goroutine struct {
+	id    string
+	stack string
+	// top is the function of the top frame, e.g. "testing.(*T).Run".
+	top string
+}
+
+// topIn reports whether the top function is any of funcs, or a closure of
+// them like "runtime.ensureSigM.func1".
+func (g goroutine) topIn(funcs []string) bool {
+	for _, f := range funcs {
+		if g.top == f || strings.HasPrefix(g.top, f+".func") {
+			return true
+		}
+	}
+	return false
+}
+
+func (g goroutine) contains(subs []string) bool {
+	for _, s := range subs {
+		if strings.Contains(g.stack, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// goroutines returns all the goroutines except the current one.
+func goroutines() []goroutine {
+	buf := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, len(buf)*2)
+	}
+
+	blocks := bytes.Split(buf, []byte("\n\n"))
+	gs := make([]goroutine, 0, len(blocks))
+	for i, b := range blocks {
+		if i == 0 {
+			// The first one is always the current goroutine.
+			continue
+		}
+		stack := strings.TrimSpace(string(b))
+		// The header is like "goroutine 18 [chan receive]:".
+		fields := strings.Fields(stack)
+		if len(fields) < 2 || fields[0] != "goroutine" {
+			continue
+		}
+		g := goroutine{id: fields[1], stack: stack}
+		// The top frame is like "testing.(*T).Run(0xc000102340, ...)".
+		if lines := strings.SplitN(stack, "\n", 3); len(lines) >= 2 {
+			if i := strings.LastIndexByte(lines[1], '('); i > 0 {
+				g.top = lines[1][:i]
+			}
+		}
+		gs = append(gs, g)
+	}
+	return gs
+}
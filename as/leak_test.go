@@ -0,0 +1,51 @@
+package as_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/elvinchan/util-collects/as"
+)
+
+func TestNoGoroutineLeak(t *testing.T) {
+	assertOk(t, "Exited", func(t testing.TB) {
+		defer as.NoGoroutineLeak(t)()
+		done := make(chan struct{})
+		go func() {
+			time.Sleep(time.Millisecond * 50)
+			close(done)
+		}()
+	})
+
+	stop := make(chan struct{})
+	defer close(stop)
+	assertFailWith(t, "Leaked", func(t testing.TB) {
+		defer as.NoGoroutineLeak(t, as.LeakWithGrace(time.Millisecond*100))()
+		go leakedLoop(stop)
+	}, "leaked: 1", "as_test.leakedLoop")
+
+	assertFailWith(t, "LeakedInSubtest", func(t testing.TB) {
+		stop, done := make(chan struct{}), make(chan struct{})
+		defer func() {
+			close(stop)
+			<-done
+		}()
+		defer as.NoGoroutineLeak(t, as.LeakWithGrace(time.Millisecond*100))()
+		go func() {
+			defer close(done)
+			t.(*testTester).Run("Blocked", func(t *testing.T) {
+				leakedLoop(stop)
+			})
+		}()
+	}, "leaked: 1", "as_test.leakedLoop")
+
+	assertOk(t, "Ignored", func(t testing.TB) {
+		defer as.NoGoroutineLeak(t, as.LeakWithGrace(time.Millisecond*100),
+			as.LeakWithIgnore("as_test.leakedLoop"))()
+		go leakedLoop(stop)
+	})
+}
+
+func leakedLoop(stop chan struct{}) {
+	<-stop
+}
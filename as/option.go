@@ -5,3 +5,10 @@ func (a *As) FailDirectly(b bool) *As {
 	a.failDirectly.Store(b)
 	return a
 }
+
+// WithClock specified the clock used by checks which wait, e.g. Eventually
+// and Never, defaults to the real time.
+func (a *As) WithClock(clock Clock) *As {
+	a.clock = clock
+	return a
+}
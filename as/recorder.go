@@ -0,0 +1,207 @@
+package as
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Anything matches any argument of a call in expectations.
+var Anything interface{} = anything{}
+
+type anything struct{}
+
+// Recorder records calls of methods on a fake implementation of interface,
+// and checks them against expectations. It is usually embedded in a fake:
+//
+//	type fakeModProvider struct{ *as.Recorder }
+//
+//	func (f fakeModProvider) Chmod(name string, mode os.FileMode) error {
+//		return f.Record("Chmod", name, mode).Error(0)
+//	}
+type Recorder struct {
+	mu           sync.Mutex
+	calls        []Call
+	unexpected   []Call
+	expectations []*Expectation
+}
+
+// Call is a recorded call of method.
+type Call struct {
+	Method  string
+	Args    []interface{}
+	Returns Returns
+}
+
+func (c Call) String() string {
+	return fmt.Sprintf("%s(%s)", c.Method, formatArgs(c.Args))
+}
+
+// Returns are the values returned by a recorded call.
+type Returns []interface{}
+
+// Get returns the i-th value, or nil if out of range.
+func (r Returns) Get(i int) interface{} {
+	if i < 0 || i >= len(r) {
+		return nil
+	}
+	return r[i]
+}
+
+// Error returns the i-th value as error, or nil if it is not an error.
+func (r Returns) Error(i int) error {
+	err, _ := r.Get(i).(error)
+	return err
+}
+
+// atLeastOnce is the times of Expectation by default.
+const atLeastOnce = -1
+
+// Expectation is an expected call of method, created by Recorder.ExpectCall.
+type Expectation struct {
+	method  string
+	args    []interface{}
+	returns Returns
+	times   int
+	calls   int
+}
+
+// Return specified the values returned by the expected call.
+func (e *Expectation) Return(values ...interface{}) *Expectation {
+	e.returns = values
+	return e
+}
+
+// Times specified the exact times of the expected call, defaults to at
+// least once. Times(0) means the call is never expected, like Never.
+func (e *Expectation) Times(n int) *Expectation {
+	e.times = n
+	return e
+}
+
+// Never specified the call is never expected, so that any matched call is
+// reported as unexpected.
+func (e *Expectation) Never() *Expectation {
+	return e.Times(0)
+}
+
+func (e *Expectation) String() string {
+	return fmt.Sprintf("%s(%s)", e.method, formatArgs(e.args))
+}
+
+func (e *Expectation) matches(method string, args []interface{}) bool {
+	if e.method != method || len(e.args) != len(args) {
+		return false
+	}
+	for i, arg := range e.args {
+		if arg != Anything && !objectsAreEqual(arg, args[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// NewRecorder create a new Recorder without any expectation.
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// ExpectCall adds an expectation of calling method with args. Anything
+// can be used for arguments which should not be checked.
+func (r *Recorder) ExpectCall(method string, args ...interface{}) *Expectation {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e := &Expectation{
+		method: method,
+		args:   args,
+		times:  atLeastOnce,
+	}
+	r.expectations = append(r.expectations, e)
+	return e
+}
+
+// Record records a call of method with args, and returns the values of the
+// first matched expectation which has not been called enough times. Calls
+// without any matched expectation are recorded as unexpected.
+func (r *Recorder) Record(method string, args ...interface{}) Returns {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	call := Call{
+		Method: method,
+		Args:   args,
+	}
+	for _, e := range r.expectations {
+		if e.matches(method, args) && (e.times == atLeastOnce || e.calls < e.times) {
+			e.calls++
+			call.Returns = e.returns
+			r.calls = append(r.calls, call)
+			return call.Returns
+		}
+	}
+	r.calls = append(r.calls, call)
+	r.unexpected = append(r.unexpected, call)
+	return nil
+}
+
+// Calls returns all the recorded calls in order.
+func (r *Recorder) Calls() []Call {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Call(nil), r.calls...)
+}
+
+// AssertExpectations checks that all the expectations of recorder have been
+// met, and there is no unexpected call.
+func (c *As) AssertExpectations(r *Recorder, msgAndArgs ...interface{}) {
+	c.Helper()
+	r.mu.Lock()
+	var unmet []string
+	for _, e := range r.expectations {
+		switch {
+		case e.times == atLeastOnce && e.calls == 0:
+			unmet = append(unmet, fmt.Sprintf("%s%s: want at least once, got 0", prefix, e))
+		case e.times != atLeastOnce && e.calls != e.times:
+			unmet = append(unmet, fmt.Sprintf("%s%s: want %d times, got %d",
+				prefix, e, e.times, e.calls))
+		}
+	}
+	unexpected := make([]string, 0, len(r.unexpected))
+	for _, call := range r.unexpected {
+		unexpected = append(unexpected, prefix+call.String())
+	}
+	r.mu.Unlock()
+
+	if len(unmet) == 0 && len(unexpected) == 0 {
+		return
+	}
+	var details []string
+	if len(unmet) > 0 {
+		details = append(details, "unmet:")
+		details = append(details, unmet...)
+	}
+	if len(unexpected) > 0 {
+		details = append(details, "unexpected:")
+		details = append(details, unexpected...)
+	}
+	c.fail("expectations not met", details, msgAndArgs...)
+}
+
+// AssertExpectations asserts that all the expectations of recorder have
+// been met, and there is no unexpected call.
+func AssertExpectations(t testing.TB, r *Recorder, msgAndArgs ...interface{}) {
+	t.Helper()
+	directlyAs(t).AssertExpectations(r, msgAndArgs...)
+}
+
+func formatArgs(args []interface{}) string {
+	s := make([]string, len(args))
+	for i, arg := range args {
+		if arg == Anything {
+			s[i] = "as.Anything"
+		} else {
+			s[i] = truncate(fmt.Sprintf("%#v", arg))
+		}
+	}
+	return strings.Join(s, ", ")
+}
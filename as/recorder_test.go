@@ -0,0 +1,75 @@
+package as_test
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/elvinchan/util-collects/as"
+)
+
+type fakeModProvider struct{ *as.Recorder }
+
+func (f fakeModProvider) Chmod(name string, mode os.FileMode) error {
+	return f.Record("Chmod", name, mode).Error(0)
+}
+
+func (f fakeModProvider) Stat(name string) (os.FileInfo, error) {
+	ret := f.Record("Stat", name)
+	fi, _ := ret.Get(0).(os.FileInfo)
+	return fi, ret.Error(1)
+}
+
+func TestRecorder(t *testing.T) {
+	errDenied := errors.New("permission denied")
+	assertOk(t, "Met", func(t testing.TB) {
+		f := fakeModProvider{as.NewRecorder()}
+		f.ExpectCall("Chmod", "a", os.FileMode(0644)).Return(nil).Times(1)
+		f.ExpectCall("Chmod", as.Anything, os.FileMode(0600)).Return(errDenied)
+		f.ExpectCall("Stat", "a").Return(nil, os.ErrNotExist)
+
+		as.NoError(t, f.Chmod("a", 0644))
+		as.Equal(t, f.Chmod("b", 0600), errDenied)
+		as.Equal(t, f.Chmod("c", 0600), errDenied)
+		_, err := f.Stat("a")
+		as.Equal(t, err, os.ErrNotExist)
+		as.AssertExpectations(t, f.Recorder)
+		as.Len(t, f.Calls(), 4)
+		as.Equal(t, f.Calls()[1].String(), `Chmod("b", 0x180)`)
+	})
+
+	assertFailWith(t, "Unmet", func(t testing.TB) {
+		f := fakeModProvider{as.NewRecorder()}
+		f.ExpectCall("Chmod", "a", os.FileMode(0644)).Times(2)
+		f.ExpectCall("Stat", "a")
+
+		_ = f.Chmod("a", 0644)
+		_ = f.Chmod("a", 0600)
+		as.AssertExpectations(t, f.Recorder)
+	}, "unmet:", `Chmod("a", 0x1a4): want 2 times, got 1`,
+		`Stat("a"): want at least once, got 0`,
+		"unexpected:", `Chmod("a", 0x180)`)
+
+	assertFailWith(t, "TooMany", func(t testing.TB) {
+		f := fakeModProvider{as.NewRecorder()}
+		f.ExpectCall("Chmod", "a", os.FileMode(0644)).Times(1)
+
+		_ = f.Chmod("a", 0644)
+		_ = f.Chmod("a", 0644)
+		as.AssertExpectations(t, f.Recorder)
+	}, "unexpected:", `Chmod("a", 0x1a4)`)
+
+	assertOk(t, "NeverCalled", func(t testing.TB) {
+		f := fakeModProvider{as.NewRecorder()}
+		f.ExpectCall("Chmod", as.Anything, as.Anything).Never()
+		as.AssertExpectations(t, f.Recorder)
+	})
+	assertFailWith(t, "Never", func(t testing.TB) {
+		f := fakeModProvider{as.NewRecorder()}
+		f.ExpectCall("Chmod", as.Anything, as.Anything).Times(0)
+		f.ExpectCall("Stat", "a").Never()
+
+		_ = f.Chmod("a", 0644)
+		as.AssertExpectations(t, f.Recorder)
+	}, "unexpected:", `Chmod("a", 0x1a4)`)
+}
@@ -0,0 +1,90 @@
+package as
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/elvinchan/util-collects/sync/atomic"
+)
+
+// Soft create a new As object for the specified testing.TB, which buffers
+// failures of checks instead of reporting them immediately. All buffered
+// failures are reported as one combined report when the test finishes.
+func Soft(t testing.TB) *As {
+	a := newSoft(t)
+	t.Cleanup(func() {
+		New(t).FailDirectly(a.failDirectly.Load()).report(a.soft.take())
+	})
+	return a
+}
+
+// Collect runs fn with an As object which buffers failures of checks, and
+// reports all of them as one combined report after fn returns.
+func (c *As) Collect(fn func(a *As)) {
+	c.Helper()
+	a := newSoft(c.TB)
+	fn(a)
+	c.report(a.soft.take())
+}
+
+// newSoft create a new As object in soft mode.
+func newSoft(t testing.TB) *As {
+	return &As{
+		t,
+		3,
+		atomic.Bool{},
+		strings.Builder{},
+		&softCollector{},
+		nil,
+		nil,
+	}
+}
+
+// report reports failures as one combined, numbered report.
+func (c *As) report(failures []string) {
+	if len(failures) == 0 {
+		return
+	}
+	c.Helper()
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "\n%d failure(s):\n", len(failures))
+	for i, f := range failures {
+		fmt.Fprintf(&sb, "#%d:%s", i+1, f)
+	}
+	if c.soft != nil {
+		c.soft.add(sb.String())
+		return
+	}
+	c.TB.Error(sb.String())
+
+	if c.failDirectly.Load() {
+		c.FailNow()
+	} else {
+		c.Fail()
+	}
+}
+
+// softCollector buffers the failures of an As object in soft mode.
+type softCollector struct {
+	// mu guards failures, and also the info of As object which is written
+	// by fail.
+	mu       sync.Mutex
+	failures []string
+}
+
+func (s *softCollector) add(failure string) {
+	s.mu.Lock()
+	s.failures = append(s.failures, failure)
+	s.mu.Unlock()
+}
+
+func (s *softCollector) take() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	failures := s.failures
+	s.failures = nil
+	return failures
+}
@@ -0,0 +1,121 @@
+package as
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Hooks of suite, which are optional methods of the struct passed to Suite.
+type (
+	suiteSetup interface {
+		SetupSuite(a *As)
+	}
+	suiteTeardown interface {
+		TeardownSuite(a *As)
+	}
+	testSetup interface {
+		SetupTest(a *As)
+	}
+	testTeardown interface {
+		TeardownTest(a *As)
+	}
+)
+
+// SuiteOption specified option for suite runs.
+type SuiteOption func(*suite)
+
+type suite struct {
+	parallel    map[string]bool
+	parallelAll bool
+}
+
+// SuiteWithParallel specified tests of the suite which run in parallel with
+// each other by t.Parallel(), or all tests if no name is specified. Hooks
+// and tests running in parallel share the same suite struct, so the state
+// of it must be synchronized.
+func SuiteWithParallel(names ...string) SuiteOption {
+	return func(s *suite) {
+		if len(names) == 0 {
+			s.parallelAll = true
+		}
+		for _, name := range names {
+			s.parallel[name] = true
+		}
+	}
+}
+
+// Suite runs each method of s which named with prefix "Test" and takes an
+// *As argument as a subtest of t, with its own As object. The hooks below
+// are called if s implements them:
+//
+//	SetupSuite(a *As)    // before all tests
+//	SetupTest(a *As)     // before each test, with the As object of test
+//	TeardownTest(a *As)  // after each test, with the As object of test
+//	TeardownSuite(a *As) // after all tests, including parallel ones
+func Suite(t *testing.T, s interface{}, opts ...SuiteOption) {
+	t.Helper()
+	st := &suite{
+		parallel: make(map[string]bool),
+	}
+	for _, opt := range opts {
+		opt(st)
+	}
+
+	a := New(t)
+	v := reflect.ValueOf(s)
+	asType := reflect.TypeOf(a)
+	var tests []reflect.Method
+	var invalid []string
+	for i := 0; i < v.NumMethod(); i++ {
+		m := v.Type().Method(i)
+		if !strings.HasPrefix(m.Name, "Test") {
+			continue
+		}
+		if m.Type.NumIn() != 2 || m.Type.In(1) != asType || m.Type.NumOut() != 0 {
+			invalid = append(invalid, fmt.Sprintf("%s%s", m.Name, m.Type))
+			continue
+		}
+		tests = append(tests, m)
+	}
+	if len(invalid) > 0 {
+		a.fail("invalid operation", append([]string{
+			"test methods should be like func(a *as.As):",
+		}, invalid...))
+		return
+	}
+
+	if ss, ok := s.(suiteSetup); ok {
+		ss.SetupSuite(a)
+		if t.Failed() {
+			return
+		}
+	}
+	if sd, ok := s.(suiteTeardown); ok {
+		t.Cleanup(func() {
+			sd.TeardownSuite(a)
+		})
+	}
+
+	for _, m := range tests {
+		m := m
+		parallel := st.parallelAll || st.parallel[m.Name]
+		t.Run(m.Name, func(t *testing.T) {
+			if parallel {
+				t.Parallel()
+			}
+			a := New(t)
+			if ts, ok := s.(testSetup); ok {
+				ts.SetupTest(a)
+				if t.Failed() {
+					return
+				}
+			}
+			if tt, ok := s.(testTeardown); ok {
+				defer tt.TeardownTest(a)
+			}
+			v.Method(m.Index).Call([]reflect.Value{reflect.ValueOf(a)})
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package as_test
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/elvinchan/util-collects/as"
+)
+
+type hookSuite struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (s *hookSuite) record(event string) {
+	s.mu.Lock()
+	s.events = append(s.events, event)
+	s.mu.Unlock()
+}
+
+func (s *hookSuite) SetupSuite(a *as.As)    { s.record("SetupSuite") }
+func (s *hookSuite) TeardownSuite(a *as.As) { s.record("TeardownSuite") }
+func (s *hookSuite) SetupTest(a *as.As)     { s.record("SetupTest " + a.Name()) }
+func (s *hookSuite) TeardownTest(a *as.As)  { s.record("TeardownTest " + a.Name()) }
+
+func (s *hookSuite) TestA(a *as.As) {
+	s.record("TestA")
+	a.Equal(a.Name(), "TestSuite/Sequential/TestA")
+}
+
+func (s *hookSuite) TestB(a *as.As) {
+	s.record("TestB")
+}
+
+type parallelSuite struct {
+	hookSuite
+}
+
+func (s *parallelSuite) TestA(a *as.As) { s.record("TestA") }
+func (s *parallelSuite) TestB(a *as.As) { s.record("TestB") }
+func (s *parallelSuite) TestC(a *as.As) { s.record("TestC") }
+
+func TestSuite(t *testing.T) {
+	s := &hookSuite{}
+	t.Run("Sequential", func(t *testing.T) {
+		as.Suite(t, s)
+	})
+	as.Equal(t, s.events, []string{
+		"SetupSuite",
+		"SetupTest TestSuite/Sequential/TestA", "TestA",
+		"TeardownTest TestSuite/Sequential/TestA",
+		"SetupTest TestSuite/Sequential/TestB", "TestB",
+		"TeardownTest TestSuite/Sequential/TestB",
+		"TeardownSuite",
+	})
+
+	p := &parallelSuite{}
+	t.Run("Parallel", func(t *testing.T) {
+		as.Suite(t, p, as.SuiteWithParallel("TestA", "TestB"))
+	})
+	// Parallel tests are paused until the sequential ones finished.
+	as.Len(t, p.events, 11)
+	as.Equal(t, p.events[:4], []string{
+		"SetupSuite",
+		"SetupTest TestSuite/Parallel/TestC", "TestC",
+		"TeardownTest TestSuite/Parallel/TestC",
+	})
+	as.Equal(t, p.events[10], "TeardownSuite")
+}
+
+func TestStackOfReflection(t *testing.T) {
+	// reflection calls are only skipped in stack when called by Suite
+	assertFailWith(t, "Call", func(t testing.TB) {
+		reflect.ValueOf(func() {
+			as.True(t, false)
+		}).Call(nil)
+	}, "reflect/value.go", "suite_test.go")
+}
@@ -0,0 +1,54 @@
+//go:build go1.18
+// +build go1.18
+
+package as
+
+import (
+	"fmt"
+	"testing"
+)
+
+// Case is a named case of Table, with the input passed to the function
+// under test and the output wanted from it.
+type Case[In, Out any] struct {
+	Name string
+	In   In
+	Want Out
+	// Only specified running only the cases marked as Only in the table.
+	Only bool
+	// Skip specified skipping the case.
+	Skip bool
+}
+
+// Table runs fn with each case as a subtest of t, and checks that the
+// output of it equals to the case wanted. Each subtest has its own As
+// object passed to fn, of which failures are printed with the case input.
+func Table[In, Out any](t *testing.T, cases []Case[In, Out],
+	fn func(a *As, in In) Out) {
+	t.Helper()
+	focused := false
+	for _, c := range cases {
+		if c.Only {
+			focused = true
+			break
+		}
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.Name, func(t *testing.T) {
+			t.Helper()
+			if c.Skip {
+				t.Skip("skipped by case")
+			}
+			if focused && !c.Only {
+				t.Skip("skipped by other cases marked as Only")
+			}
+			a := New(t)
+			a.caseInfo = []string{
+				"name: " + c.Name,
+				"in: " + truncate(fmt.Sprintf("%+v", c.In)),
+			}
+			a.Equal(fn(a, c.In), c.Want)
+		})
+	}
+}
@@ -0,0 +1,47 @@
+//go:build go1.18
+// +build go1.18
+
+package as_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/elvinchan/util-collects/as"
+)
+
+func TestTable(t *testing.T) {
+	type in struct {
+		s    string
+		n    int
+		sep  string
+		fail bool
+	}
+	var ran []string
+	as.Table(t, []as.Case[in, string]{
+		{Name: "Repeat", In: in{s: "a", n: 3}, Want: "aaa", Only: true},
+		{Name: "RepeatWithSep", In: in{s: "a", n: 2, sep: ","}, Want: "a,a", Only: true},
+		{Name: "Skipped", In: in{fail: true}, Skip: true, Only: true},
+		{Name: "NotFocused", In: in{fail: true}},
+	}, func(a *as.As, in in) string {
+		ran = append(ran, a.Name())
+		a.False(in.fail)
+		return strings.TrimSuffix(strings.Repeat(in.s+in.sep, in.n), in.sep)
+	})
+	as.Equal(t, ran, []string{"TestTable/Repeat", "TestTable/RepeatWithSep"})
+
+	// failures are reported to the fake TB, with the input of case
+	tester := &testTester{}
+	as.Table(t, []as.Case[in, string]{
+		{Name: "Mismatch", In: in{s: "a", n: 2, sep: "-"}, Want: "a,a"},
+	}, func(a *as.As, in in) string {
+		tester.T = a.TB.(*testing.T)
+		a.TB = tester
+		return strings.TrimSuffix(strings.Repeat(in.s+in.sep, in.n), in.sep)
+	})
+	for _, s := range []string{"should equal", "case:", "name: Mismatch", "in: {s:a n:2 sep:- fail:false}"} {
+		if !strings.Contains(tester.errorMsg, s) {
+			t.Fatalf("Should have failed with %q, got:\n%s", s, tester.errorMsg)
+		}
+	}
+}
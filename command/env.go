@@ -0,0 +1,93 @@
+package command
+
+import (
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// environ specified the environment of process, which inherits the
+// environment of current process by default, overridden by the specified
+// variables.
+type environ struct {
+	clean       bool
+	allow, deny []string
+	vars        []string
+}
+
+func (e *environ) add(vars ...string) {
+	e.vars = append(e.vars, vars...)
+}
+
+func (e *environ) addMap(vars map[string]string) {
+	keys := make([]string, 0, len(vars))
+	for k := range vars {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		e.vars = append(e.vars, k+"="+vars[k])
+	}
+}
+
+// build returns the environment of process, in which each key appears only
+// once, with the value of its last occurrence, at the position of its first
+// occurrence.
+func (e *environ) build() []string {
+	var inherited []string
+	if !e.clean {
+		for _, kv := range os.Environ() {
+			key := envKey(kv)
+			if (e.allow == nil || matchEnv(e.allow, key)) && !matchEnv(e.deny, key) {
+				inherited = append(inherited, kv)
+			}
+		}
+	}
+
+	env := make([]string, 0, len(inherited)+len(e.vars))
+	index := make(map[string]int, cap(env))
+	for _, kvs := range [][]string{inherited, e.vars} {
+		for _, kv := range kvs {
+			key := envKey(kv)
+			if runtime.GOOS == "windows" {
+				// environment variables are case-insensitive on windows
+				key = strings.ToUpper(key)
+			}
+			if i, ok := index[key]; ok {
+				env[i] = kv
+				continue
+			}
+			index[key] = len(env)
+			env = append(env, kv)
+		}
+	}
+	return env
+}
+
+func envKey(kv string) string {
+	// Skip the first byte, since keys of some special variables on windows
+	// start with "=", like "=C:=C:\dir".
+	if len(kv) == 0 {
+		return kv
+	}
+	if i := strings.IndexByte(kv[1:], '='); i >= 0 {
+		return kv[:i+1]
+	}
+	return kv
+}
+
+// matchEnv reports whether key matches any of patterns, which are either
+// exact keys or prefixes of keys ending with "*", e.g. "LC_*".
+func matchEnv(patterns []string, key string) bool {
+	for _, p := range patterns {
+		if strings.HasSuffix(p, "*") {
+			if strings.HasPrefix(key, p[:len(p)-1]) {
+				return true
+			}
+		} else if p == key {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,72 @@
+//go:build !windows
+// +build !windows
+
+package command
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/elvinchan/util-collects/as"
+)
+
+func TestEnv(t *testing.T) {
+	t.Setenv("COMMAND_TEST_A", "a")
+	t.Setenv("COMMAND_TEST_B", "b")
+	t.Setenv("COMMAND_OTHER", "other")
+
+	t.Run("Inherit", func(t *testing.T) {
+		b, err := RunBytes("env", RunWithEnv("COMMAND_TEST_A=override", "COMMAND_TEST_C=c"))
+		as.NoError(t, err)
+		env := strings.Split(strings.TrimSpace(string(b)), "\n")
+		as.Contains(t, env, "PATH="+os.Getenv("PATH"))
+		as.Contains(t, env, "COMMAND_TEST_A=override")
+		as.Contains(t, env, "COMMAND_TEST_B=b")
+		as.Contains(t, env, "COMMAND_TEST_C=c")
+		as.NotContains(t, env, "COMMAND_TEST_A=a")
+	})
+
+	t.Run("Clean", func(t *testing.T) {
+		b, err := RunBytes("/usr/bin/env", RunWithCleanEnv())
+		as.NoError(t, err)
+		as.Empty(t, b)
+
+		b, err = RunBytes("/usr/bin/env", RunWithCleanEnv(), RunWithEnvMap(map[string]string{
+			"B": "2",
+			"A": "1",
+		}))
+		as.NoError(t, err)
+		as.Equal(t, string(b), "A=1\nB=2\n")
+	})
+
+	t.Run("Allow", func(t *testing.T) {
+		e := environ{allow: []string{"COMMAND_TEST_*", "COMMAND_NOT_EXISTS"}}
+		e.add("COMMAND_TEST_B=override")
+		as.Equal(t, e.build(), []string{"COMMAND_TEST_A=a", "COMMAND_TEST_B=override"})
+	})
+
+	t.Run("Deny", func(t *testing.T) {
+		e := environ{deny: []string{"COMMAND_TEST_*"}}
+		env := e.build()
+		as.Contains(t, env, "COMMAND_OTHER=other")
+		as.NotContains(t, env, "COMMAND_TEST_A=a")
+		as.NotContains(t, env, "COMMAND_TEST_B=b")
+
+		var buf strings.Builder
+		p, err := Start("/usr/bin/env", StartWithEnvDeny("COMMAND_*"),
+			StartWithEnv("COMMAND_TEST_C=c"), StartWithStdout(&buf))
+		as.NoError(t, err)
+		as.NoError(t, p.Wait())
+		as.NotContains(t, buf.String(), "COMMAND_TEST_A")
+		as.NotContains(t, buf.String(), "COMMAND_OTHER")
+		as.Contains(t, buf.String(), "COMMAND_TEST_C=c\n")
+	})
+
+	t.Run("Dedup", func(t *testing.T) {
+		e := environ{clean: true}
+		e.add("A=1", "B=2", "A=3", "C", "=C:=C:\\", "=C:=D:\\")
+		e.addMap(map[string]string{"B": "4"})
+		as.Equal(t, e.build(), []string{"A=3", "B=4", "C", "=C:=D:\\"})
+	})
+}
@@ -0,0 +1,154 @@
+package command
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"strings"
+	"sync"
+)
+
+// Stream identifies an output stream of process.
+type Stream uint8
+
+const (
+	Stdout Stream = iota + 1
+	Stderr
+)
+
+func (s Stream) String() string {
+	switch s {
+	case Stdout:
+		return "stdout"
+	case Stderr:
+		return "stderr"
+	default:
+		return ""
+	}
+}
+
+// LineFunc receives a line of output without the line ending, and the stream
+// it comes from. Returning an error aborts the process.
+type LineFunc func(line string, stream Stream) error
+
+type line struct {
+	text   string
+	stream Stream
+	// err aborts the process, e.g. line is too long.
+	err error
+}
+
+// RunLines runs command and delivers lines of stdout and stderr to fn as
+// they arrive, until process exit. Lines are delivered one by one, in the
+// order of arrival between streams. If fn returns an error, the process is
+// killed and the error is returned. Lines longer than RunWithSize abort the
+// process likewise, with an error of data beyond limit.
+func RunLines(name string, fn LineFunc, opts ...RunOption) error {
+	r, cancel := newRunner(opts)
+	defer cancel()
+	ctx, abort := context.WithCancel(r.ctx)
+	defer abort()
+	r.ctx = ctx
+
+	cmd := r.command(name)
+	var pipes []readPipe
+	var closeAfterStart []io.Closer
+	defer func() {
+		for _, p := range pipes {
+			p.Close()
+		}
+	}()
+	if r.pty != nil {
+		master, slave, err := ptyPipe(cmd, *r.pty, r.stdin)
+		if err != nil {
+			return err
+		}
+		pipes, closeAfterStart = []readPipe{master}, []io.Closer{slave}
+	} else {
+		outReader, errReader, writers, err := separatePipes(cmd)
+		if err != nil {
+			return err
+		}
+		pipes, closeAfterStart = []readPipe{outReader, errReader}, writers
+	}
+	err := r.attr.start(cmd)
+	for _, c := range closeAfterStart {
+		c.Close()
+	}
+	if err != nil {
+		return err
+	}
+	defer r.watch(cmd)()
+
+	lines := make(chan line)
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	streams := []Stream{Stdout, Stderr}
+	wg.Add(len(pipes))
+	for i, p := range pipes {
+		go scanLines(p, streams[i], r.size, lines, &wg)
+	}
+	go func() {
+		wg.Wait()
+		close(lines)
+		close(done)
+	}()
+	errc := make(chan error, 1)
+	go func() {
+		errc <- waitAfter(r.ctx.Done(), cmd, done, pipes...)
+	}()
+
+	var abortErr error
+	for l := range lines {
+		if abortErr != nil {
+			// drain the rest to let readers exit
+			continue
+		}
+		if abortErr = l.err; abortErr == nil {
+			abortErr = fn(l.text, l.stream)
+		}
+		if abortErr != nil {
+			abort()
+		}
+	}
+	err = <-errc
+
+	if abortErr != nil {
+		return abortErr
+	}
+	if r.ctx.Err() != nil {
+		return r.ctx.Err()
+	}
+	return err
+}
+
+// scanLines sends lines read from r, which are limited to size bytes
+// without line ending if size is not 0.
+func scanLines(r io.Reader, stream Stream, size uint64, lines chan<- line, wg *sync.WaitGroup) {
+	defer wg.Done()
+	br := bufio.NewReader(r)
+	var buf []byte
+	for {
+		b, err := br.ReadSlice('\n')
+		buf = append(buf, b...)
+		if err == bufio.ErrBufferFull {
+			if size > 0 && uint64(len(buf)) > size {
+				lines <- line{err: beyondLimitError(size)}
+				return
+			}
+			continue
+		}
+		if len(buf) > 0 {
+			s := strings.TrimSuffix(strings.TrimSuffix(string(buf), "\n"), "\r")
+			if size > 0 && uint64(len(s)) > size {
+				lines <- line{err: beyondLimitError(size)}
+				return
+			}
+			lines <- line{text: s, stream: stream}
+			buf = buf[:0]
+		}
+		if err != nil {
+			return
+		}
+	}
+}
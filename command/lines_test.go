@@ -0,0 +1,56 @@
+//go:build !windows
+// +build !windows
+
+package command
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/elvinchan/util-collects/as"
+)
+
+func TestRunLines(t *testing.T) {
+	t.Run("Normal", func(t *testing.T) {
+		var got []string
+		err := RunLines("sh", func(line string, stream Stream) error {
+			got = append(got, stream.String()+": "+line)
+			return nil
+		}, RunWithArgs("-c", "echo a; sleep 0.1; echo 1>&2 b; sleep 0.1; printf c"))
+		as.NoError(t, err)
+		as.Equal(t, got, []string{"stdout: a", "stderr: b", "stdout: c"})
+	})
+
+	t.Run("Abort", func(t *testing.T) {
+		errStop := errors.New("stop")
+		var got []string
+		start := time.Now()
+		err := RunLines("sh", func(line string, stream Stream) error {
+			got = append(got, line)
+			return errStop
+		}, RunWithArgs("-c", "echo a; sleep 2; echo b"))
+		as.Equal(t, err, errStop)
+		as.Equal(t, got, []string{"a"})
+		as.Less(t, time.Since(start), time.Second)
+	})
+
+	t.Run("Size", func(t *testing.T) {
+		var got []string
+		err := RunLines("sh", func(line string, stream Stream) error {
+			got = append(got, line)
+			return nil
+		}, RunWithArgs("-c", "echo 1234; echo 12345678; head -c 100000 /dev/zero; sleep 2"),
+			RunWithSize(8))
+		as.ErrorContains(t, err, "data beyond limit")
+		as.Equal(t, got, []string{"1234", "12345678"})
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		err := RunLines("sleep", func(line string, stream Stream) error {
+			return nil
+		}, RunWithArgs("2"), RunWithTimeout(time.Millisecond*200))
+		as.Equal(t, err, context.DeadlineExceeded)
+	})
+}
@@ -0,0 +1,240 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// Stage is a command of pipeline.
+type Stage struct {
+	Name string
+	Opts []RunOption
+}
+
+// NewStage create a stage runs command name with opts.
+func NewStage(name string, opts ...RunOption) Stage {
+	return Stage{Name: name, Opts: opts}
+}
+
+// Pipe is a pipeline of commands created by Pipeline.
+type Pipe struct {
+	stages []Stage
+}
+
+// Pipeline create a pipeline of stages, which stdout of each stage is
+// connected to stdin of the next one, like `a | b | c` of shell.
+func Pipeline(stages ...Stage) *Pipe {
+	return &Pipe{stages: stages}
+}
+
+// StageError reports a failed stage of pipeline.
+type StageError struct {
+	// Index is the index of stage in pipeline.
+	Index int
+	Name  string
+	Err   error
+}
+
+func (e *StageError) Error() string {
+	return fmt.Sprintf("stage %d (%s): %s", e.Index, e.Name, e.Err)
+}
+
+func (e *StageError) Unwrap() error {
+	return e.Err
+}
+
+// Run runs all stages of pipeline until they exit, and returns the result
+// of each stage. Only the last stage has Stdout, which is limited by
+// RunWithSize, and RunWithErrToOutput of stage merges its stderr into the
+// output to the next stage.
+//
+// The context, timeout, size and graceful stop are shared by all stages,
+// which are specified by opts instead of options of stages, and so is the
+// stdin of pipeline if specified by opts.
+//
+// Like `set -o pipefail` of shell, if any stage exits unsuccessfully, the
+// error is a *StageError of the last one, which wraps an *ExitError.
+func (p *Pipe) Run(opts ...RunOption) ([]*Result, error) {
+	if len(p.stages) == 0 {
+		return nil, errors.New("no stage in pipeline")
+	}
+	r, cancel := newRunner(opts)
+	defer cancel()
+
+	n := len(p.stages)
+	cmds := make([]*exec.Cmd, n)
+	attrs := make([]procAttr, n)
+	results := make([]*Result, n)
+	stderrs := make([]*limitBuffer, n)
+	for i, st := range p.stages {
+		sr := &Runner{}
+		for _, opt := range st.Opts {
+			opt(sr)
+		}
+		if sr.pty != nil {
+			return nil, errors.New("pseudo-terminal is not supported by pipeline")
+		}
+		sr.ctx, sr.stop = r.ctx, r.stop
+		cmds[i] = sr.command(st.Name)
+		attrs[i] = sr.attr
+		if i == 0 && r.stdin != nil {
+			cmds[i].Stdin = r.stdin
+		}
+		if sr.errToOutput {
+			// written to the same pipe of stdout below
+			continue
+		}
+		stderrs[i] = &limitBuffer{size: r.size}
+	}
+	stdout := &limitBuffer{size: r.size}
+
+	// childFiles are closed after processes started, and readers are
+	// closed after pipeline done.
+	var childFiles, readers []*os.File
+	closeAll := func(files []*os.File) {
+		for _, f := range files {
+			f.Close()
+		}
+	}
+	defer func() {
+		closeAll(childFiles)
+		closeAll(readers)
+	}()
+	var reads sync.WaitGroup
+	capture := func(w io.Writer) (*os.File, error) {
+		pr, pw, err := os.Pipe()
+		if err != nil {
+			return nil, err
+		}
+		childFiles = append(childFiles, pw)
+		readers = append(readers, pr)
+		reads.Add(1)
+		go copyTo(w, pr, &reads)
+		return pw, nil
+	}
+	for i, cmd := range cmds {
+		if i < n-1 {
+			pr, pw, err := os.Pipe()
+			if err != nil {
+				return nil, err
+			}
+			childFiles = append(childFiles, pr, pw)
+			cmd.Stdout = pw
+			cmds[i+1].Stdin = pr
+		} else {
+			pw, err := capture(stdout)
+			if err != nil {
+				return nil, err
+			}
+			cmd.Stdout = pw
+		}
+		if stderrs[i] == nil {
+			cmd.Stderr = cmd.Stdout
+			continue
+		}
+		pw, err := capture(stderrs[i])
+		if err != nil {
+			return nil, err
+		}
+		cmd.Stderr = pw
+	}
+
+	for i, cmd := range cmds {
+		results[i] = &Result{
+			StartTime: time.Now(),
+		}
+		if err := attrs[i].start(cmd); err != nil {
+			for _, started := range cmds[:i] {
+				_ = started.Process.Kill()
+				_ = started.Wait()
+			}
+			return nil, &StageError{Index: i, Name: p.stages[i].Name, Err: err}
+		}
+	}
+	closeAll(childFiles)
+	childFiles = nil
+	killed := r.ctx.Done()
+	if r.stop != nil {
+		ps := make([]*os.Process, n)
+		for i, cmd := range cmds {
+			ps[i] = cmd.Process
+		}
+		var release func()
+		release, killed = r.stop.watch(r.ctx, ps, false)
+		defer release()
+	}
+
+	errs := make([]error, n)
+	var waits sync.WaitGroup
+	waits.Add(n)
+	for i, cmd := range cmds {
+		go func(i int, cmd *exec.Cmd) {
+			defer waits.Done()
+			errs[i] = cmd.Wait()
+			results[i].EndTime = time.Now()
+		}(i, cmd)
+	}
+	waits.Wait()
+
+	done := make(chan struct{})
+	go func() {
+		reads.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-killed:
+		// the pipes may be still held by children of killed processes
+		closeAll(readers)
+		<-done
+	}
+
+	for i, res := range results {
+		res.Duration = res.EndTime.Sub(res.StartTime)
+		res.setState(cmds[i].ProcessState)
+		if stderrs[i] != nil {
+			res.Stderr = stderrs[i].Bytes()
+		}
+	}
+	last := results[n-1]
+	last.Stdout = stdout.Bytes()
+
+	if r.ctx.Err() != nil {
+		return results, r.ctx.Err()
+	}
+	for i := n - 1; i >= 0; i-- {
+		exitErr, ok := errs[i].(*exec.ExitError)
+		if !ok {
+			if errs[i] != nil {
+				return results, &StageError{Index: i, Name: p.stages[i].Name, Err: errs[i]}
+			}
+			continue
+		}
+		errOutput := results[i].Stderr
+		if i == n-1 && stderrs[i] == nil {
+			errOutput = last.Stdout
+		}
+		return results, &StageError{
+			Index: i,
+			Name:  p.stages[i].Name,
+			Err: &ExitError{
+				ExitError:  exitErr,
+				StderrTail: tail(errOutput, stderrTailSize),
+			},
+		}
+	}
+	if stdout.beyond {
+		return results, beyondLimitError(r.size)
+	}
+	for _, b := range stderrs {
+		if b != nil && b.beyond {
+			return results, beyondLimitError(r.size)
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,108 @@
+//go:build !windows
+// +build !windows
+
+package command
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/elvinchan/util-collects/as"
+)
+
+func TestPipeline(t *testing.T) {
+	t.Run("Normal", func(t *testing.T) {
+		rs, err := Pipeline(
+			NewStage("printf", RunWithArgs("b\\na\\nc\\na\\n")),
+			NewStage("sort"),
+			NewStage("uniq", RunWithArgs("-c")),
+		).Run()
+		as.NoError(t, err)
+		as.Len(t, rs, 3)
+		as.Empty(t, rs[0].Stdout)
+		as.Equal(t, strings.Fields(string(rs[2].Stdout)), []string{"2", "a", "1", "b", "1", "c"})
+		for _, res := range rs {
+			as.Equal(t, res.ExitCode, 0)
+		}
+	})
+
+	t.Run("Stdin", func(t *testing.T) {
+		rs, err := Pipeline(
+			NewStage("tr", RunWithArgs("a-z", "A-Z")),
+			NewStage("cat"),
+		).Run(RunWithStdin(strings.NewReader("hello")))
+		as.NoError(t, err)
+		as.Equal(t, string(rs[1].Stdout), "HELLO")
+	})
+
+	t.Run("Pipefail", func(t *testing.T) {
+		rs, err := Pipeline(
+			NewStage("sh", RunWithArgs("-c", "echo first 1>&2; exit 3")),
+			NewStage("sh", RunWithArgs("-c", "cat; echo second 1>&2; exit 2")),
+			NewStage("cat"),
+		).Run()
+		var stageErr *StageError
+		as.ErrorAs(t, err, &stageErr)
+		as.Equal(t, stageErr.Index, 1)
+		as.Equal(t, stageErr.Name, "sh")
+		var exitErr *ExitError
+		as.ErrorAs(t, err, &exitErr)
+		as.Equal(t, string(exitErr.StderrTail), "second")
+		as.EqualError(t, err, "stage 1 (sh): exit status 2: second")
+		as.Equal(t, rs[0].ExitCode, 3)
+		as.Equal(t, string(rs[0].Stderr), "first\n")
+		as.Equal(t, rs[1].ExitCode, 2)
+		as.Equal(t, rs[2].ExitCode, 0)
+	})
+
+	t.Run("ErrToOutput", func(t *testing.T) {
+		rs, err := Pipeline(
+			NewStage("sh", RunWithArgs("-c", "echo out; echo err 1>&2"), RunWithErrToOutput()),
+			NewStage("sort"),
+		).Run()
+		as.NoError(t, err)
+		as.Equal(t, string(rs[1].Stdout), "err\nout\n")
+		as.Empty(t, rs[0].Stderr)
+	})
+
+	t.Run("BeyondLimit", func(t *testing.T) {
+		rs, err := Pipeline(
+			NewStage("head", RunWithArgs("-c", "2048", "/dev/zero")),
+			NewStage("cat"),
+		).Run(RunWithSize(1024))
+		as.ErrorContains(t, err, "data beyond limit")
+		as.Len(t, rs[1].Stdout, 1024)
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		start := time.Now()
+		rs, err := Pipeline(
+			NewStage("sleep", RunWithArgs("10")),
+			NewStage("cat"),
+		).Run(RunWithTimeout(time.Millisecond * 200))
+		as.ErrorIs(t, err, context.DeadlineExceeded)
+		as.Less(t, time.Since(start), time.Second*5)
+		as.Equal(t, rs[0].Signal, syscall.SIGKILL)
+	})
+
+	t.Run("StartFailed", func(t *testing.T) {
+		_, err := Pipeline(
+			NewStage("sleep", RunWithArgs("10")),
+			NewStage("command-not-exists"),
+		).Run()
+		var stageErr *StageError
+		as.ErrorAs(t, err, &stageErr)
+		as.Equal(t, stageErr.Index, 1)
+		as.True(t, errors.Is(err, exec.ErrNotFound))
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		_, err := Pipeline().Run()
+		as.Error(t, err)
+	})
+}
@@ -0,0 +1,52 @@
+package command
+
+import (
+	"errors"
+	"time"
+)
+
+// procAttr specified attributes of process, which are applied by start.
+type procAttr struct {
+	dir    string
+	cred   *credential
+	nice   *int
+	limits Limits
+	// afterStart specified that niceness and limits are applied after
+	// process started instead of before exec.
+	afterStart bool
+}
+
+type credential struct {
+	uid, gid uint32
+	groups   []uint32
+}
+
+// Limits are the resource limits of process, which are applied before the
+// command starts executing. Both the soft and hard limits are set, and zero
+// values mean no limit is set. Only supported on linux.
+//
+// Applying limits or niceness before exec requires ptrace, and starting
+// process fails if it is not permitted, e.g. denied by seccomp or Yama,
+// unless RunWithLimitsAfterStart or StartWithLimitsAfterStart is specified.
+type Limits struct {
+	// CPU is the max CPU time of process, rounded up to seconds.
+	CPU time.Duration
+	// AddressSpace is the max size of virtual memory of process in bytes.
+	AddressSpace uint64
+	// OpenFiles is the max number of file descriptors opened by process.
+	OpenFiles uint64
+	// Processes is the max number of processes of the real user of process,
+	// including the ones not started by it.
+	Processes uint64
+}
+
+func (l Limits) isZero() bool {
+	return l == Limits{}
+}
+
+// cpuSeconds returns CPU limit in seconds, rounded up.
+func (l Limits) cpuSeconds() uint64 {
+	return uint64((l.CPU + time.Second - 1) / time.Second)
+}
+
+var errLimitsNotSupported = errors.New("resource limits are only supported on linux")
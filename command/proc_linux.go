@@ -0,0 +1,121 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"syscall"
+	"unsafe"
+)
+
+// resources of rlimit, which are not all defined in syscall.
+const (
+	rlimitCPU    = 0x0
+	rlimitNProc  = 0x6
+	rlimitNoFile = 0x7
+	rlimitAS     = 0x9
+)
+
+// start starts cmd with attributes applied. The niceness and limits are
+// applied while process is stopped by ptrace right after exec, so they take
+// effect before the command executes any instruction, or right after
+// process started if specified by RunWithLimitsAfterStart.
+func (a *procAttr) start(cmd *exec.Cmd) error {
+	a.setCredential(cmd)
+	if a.nice == nil && a.limits.isZero() {
+		return cmd.Start()
+	}
+	if a.afterStart {
+		return a.startUntraced(cmd)
+	}
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Ptrace = true
+	// ptrace requests must be made from the thread which started process.
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	if err := cmd.Start(); err != nil {
+		if errors.Is(err, syscall.EPERM) {
+			return fmt.Errorf("%w (applying niceness or limits before exec requires "+
+				"ptrace, which may be denied by seccomp or Yama, "+
+				"see RunWithLimitsAfterStart)", err)
+		}
+		return err
+	}
+
+	pid := cmd.Process.Pid
+	var ws syscall.WaitStatus
+	if _, err := syscall.Wait4(pid, &ws, 0, nil); err != nil {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		return err
+	}
+	if !ws.Stopped() {
+		// process has been reaped, Wait only releases resources
+		_ = cmd.Wait()
+		return fmt.Errorf("process exited before applying attributes: %v", ws)
+	}
+	err := a.apply(pid)
+	if err == nil {
+		err = syscall.PtraceDetach(pid)
+	}
+	if err != nil {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		return err
+	}
+	return nil
+}
+
+// startUntraced starts cmd and applies the niceness and limits right after
+// process started, which may have executed some instructions already.
+func (a *procAttr) startUntraced(cmd *exec.Cmd) error {
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	if err := a.apply(cmd.Process.Pid); err != nil {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		return err
+	}
+	return nil
+}
+
+// apply applies the niceness and limits to process pid.
+func (a *procAttr) apply(pid int) error {
+	if err := a.setNice(pid); err != nil {
+		return err
+	}
+	return a.setLimits(pid)
+}
+
+func (a *procAttr) setLimits(pid int) error {
+	for _, l := range []struct {
+		resource int
+		value    uint64
+	}{
+		{rlimitCPU, a.limits.cpuSeconds()},
+		{rlimitAS, a.limits.AddressSpace},
+		{rlimitNoFile, a.limits.OpenFiles},
+		{rlimitNProc, a.limits.Processes},
+	} {
+		if l.value == 0 {
+			continue
+		}
+		if err := prlimit(pid, l.resource, &syscall.Rlimit{Cur: l.value, Max: l.value}); err != nil {
+			return fmt.Errorf("prlimit(%d): %w", l.resource, err)
+		}
+	}
+	return nil
+}
+
+func prlimit(pid, resource int, limit *syscall.Rlimit) error {
+	_, _, errno := syscall.RawSyscall6(syscall.SYS_PRLIMIT64, uintptr(pid),
+		uintptr(resource), uintptr(unsafe.Pointer(limit)), 0, 0, 0)
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
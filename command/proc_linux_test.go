@@ -0,0 +1,91 @@
+package command
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/elvinchan/util-collects/as"
+)
+
+func TestProcAttr(t *testing.T) {
+	t.Run("Dir", func(t *testing.T) {
+		dir, err := filepath.EvalSymlinks(t.TempDir())
+		as.NoError(t, err)
+		b, err := RunBytes("pwd", RunWithDir(dir))
+		as.NoError(t, err)
+		as.Equal(t, string(b), dir+"\n")
+	})
+
+	t.Run("Nice", func(t *testing.T) {
+		b, err := RunBytes("nice", RunWithNice(5))
+		as.NoError(t, err)
+		as.Equal(t, string(b), "5\n")
+	})
+
+	t.Run("Limits", func(t *testing.T) {
+		b, err := RunBytes("cat", RunWithArgs("/proc/self/limits"), RunWithLimits(Limits{
+			CPU:          time.Millisecond * 1500,
+			AddressSpace: 1 << 30,
+			OpenFiles:    64,
+			Processes:    1000,
+		}))
+		as.NoError(t, err)
+		limits := make(map[string][]string)
+		for _, line := range strings.Split(string(b), "\n") {
+			fields := strings.Fields(line)
+			if len(fields) >= 5 && fields[0] == "Max" {
+				n := len(fields)
+				limits[strings.Join(fields[:n-3], " ")] = fields[n-3 : n-1]
+			}
+		}
+		as.Equal(t, limits["Max cpu time"], []string{"2", "2"})
+		as.Equal(t, limits["Max address space"], []string{"1073741824", "1073741824"})
+		as.Equal(t, limits["Max open files"], []string{"64", "64"})
+		as.Equal(t, limits["Max processes"], []string{"1000", "1000"})
+	})
+
+	t.Run("CPULimit", func(t *testing.T) {
+		res, err := Run("sh", RunWithArgs("-c", "while :; do :; done"),
+			RunWithLimits(Limits{CPU: time.Second}), RunWithTimeout(time.Second*10))
+		as.Error(t, err)
+		// killed at the hard limit, which is the same as the soft one
+		as.Contains(t, []os.Signal{syscall.SIGXCPU, syscall.SIGKILL}, res.Signal)
+		as.Less(t, res.Duration, time.Second*5)
+	})
+
+	t.Run("Start", func(t *testing.T) {
+		var buf strings.Builder
+		p, err := Start("sh", StartWithArgs("-c", "ulimit -n; nice"), StartWithStdout(&buf),
+			StartWithLimits(Limits{OpenFiles: 32}), StartWithNice(3))
+		as.NoError(t, err)
+		as.NoError(t, p.Wait())
+		as.Equal(t, buf.String(), "32\n3\n")
+	})
+
+	t.Run("AfterStart", func(t *testing.T) {
+		var buf strings.Builder
+		p, err := StartInteractive("sh", StartWithArgs("-c", "read x; ulimit -n; nice"),
+			StartWithStdout(&buf), StartWithLimits(Limits{OpenFiles: 32}), StartWithNice(3),
+			StartWithLimitsAfterStart())
+		as.NoError(t, err)
+		// applied before the command reads the line
+		_, err = p.Input.Write([]byte("\n"))
+		as.NoError(t, err)
+		as.NoError(t, p.Wait())
+		as.Equal(t, buf.String(), "32\n3\n")
+	})
+
+	t.Run("Credential", func(t *testing.T) {
+		if os.Getuid() != 0 {
+			t.Skip("requires root")
+		}
+		b, err := RunBytes("id", RunWithCredential(65534, 65534, 65534))
+		as.NoError(t, err)
+		as.Contains(t, string(b), "uid=65534")
+		as.Contains(t, string(b), "gid=65534")
+	})
+}
@@ -0,0 +1,24 @@
+//go:build !linux && !windows
+// +build !linux,!windows
+
+package command
+
+import "os/exec"
+
+// start starts cmd with attributes applied. The niceness is applied right
+// after process started, and limits are not supported.
+func (a *procAttr) start(cmd *exec.Cmd) error {
+	if !a.limits.isZero() {
+		return errLimitsNotSupported
+	}
+	a.setCredential(cmd)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	if err := a.setNice(cmd.Process.Pid); err != nil {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+//go:build !windows
+// +build !windows
+
+package command
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// setCredential sets the credential of process to cmd if specified.
+func (a *procAttr) setCredential(cmd *exec.Cmd) {
+	if a.cred == nil {
+		return
+	}
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Credential = &syscall.Credential{
+		Uid:    a.cred.uid,
+		Gid:    a.cred.gid,
+		Groups: a.cred.groups,
+	}
+}
+
+// setNice sets the niceness of process pid if specified.
+func (a *procAttr) setNice(pid int) error {
+	if a.nice == nil {
+		return nil
+	}
+	return syscall.Setpriority(syscall.PRIO_PROCESS, pid, *a.nice)
+}
@@ -0,0 +1,18 @@
+package command
+
+import (
+	"errors"
+	"os/exec"
+)
+
+// start starts cmd with attributes applied. Only the working directory is
+// supported on windows.
+func (a *procAttr) start(cmd *exec.Cmd) error {
+	if !a.limits.isZero() {
+		return errLimitsNotSupported
+	}
+	if a.cred != nil || a.nice != nil {
+		return errors.New("credential and niceness are not supported on windows")
+	}
+	return cmd.Start()
+}
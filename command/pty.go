@@ -0,0 +1,91 @@
+package command
+
+import (
+	"errors"
+	"io"
+	"os"
+	"syscall"
+	"time"
+)
+
+// ptySize is the window size of pseudo-terminal.
+type ptySize struct {
+	rows, cols uint16
+}
+
+// ptyMaster is the master side of pseudo-terminal, reading from which gets
+// the output of terminal, and writing to which is typing into terminal.
+type ptyMaster struct {
+	f *os.File
+	// typed is closed once typing by typeInto stopped.
+	typed chan struct{}
+}
+
+// Read reads the output of terminal, returns io.EOF once the slave side is
+// closed by all processes.
+func (m *ptyMaster) Read(p []byte) (int, error) {
+	n, err := m.f.Read(p)
+	if errors.Is(err, syscall.EIO) {
+		return n, io.EOF
+	}
+	return n, err
+}
+
+func (m *ptyMaster) SetReadDeadline(t time.Time) error {
+	return m.f.SetReadDeadline(t)
+}
+
+func (m *ptyMaster) Write(p []byte) (int, error) {
+	return m.f.Write(p)
+}
+
+// Close closes terminal, and waits for typing by typeInto to stop, which
+// is blocked until reading from its reader returns.
+func (m *ptyMaster) Close() error {
+	err := m.f.Close()
+	if m.typed != nil {
+		<-m.typed
+	}
+	return err
+}
+
+// typeInto types in into terminal in background followed by EOF (Ctrl-D),
+// until terminal is closed.
+func (m *ptyMaster) typeInto(in io.Reader) {
+	m.typed = make(chan struct{})
+	go func() {
+		defer close(m.typed)
+		input := &ptyInput{m: m}
+		if _, err := io.Copy(input, in); err == nil {
+			_ = input.Close()
+		}
+	}()
+}
+
+// ptyInput types into terminal, and closing which types EOF (Ctrl-D)
+// instead of closing terminal.
+type ptyInput struct {
+	m *ptyMaster
+	// partial reports whether the last line typed is not ended by newline.
+	partial bool
+}
+
+func (in *ptyInput) Write(p []byte) (int, error) {
+	n, err := in.m.Write(p)
+	if n > 0 {
+		in.partial = p[n-1] != '\n'
+	}
+	return n, err
+}
+
+// Close types EOF, twice if the last line is not ended, as the first one
+// only sends the line typed to process.
+func (in *ptyInput) Close() error {
+	eof := []byte{4}
+	if in.partial {
+		eof = append(eof, 4)
+	}
+	_, err := in.m.Write(eof)
+	in.partial = false
+	return err
+}
@@ -0,0 +1,89 @@
+package command
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"syscall"
+	"unsafe"
+)
+
+// ptyPipe connects stdin, stdout and stderr of cmd to a new pseudo-terminal
+// of size, which is the controlling terminal of process in a new session.
+// As the new session is also a new process group, it replaces the one of
+// StartWithDetach. If in is not nil, it is typed into terminal until the
+// master is closed. The slave should be closed after cmd started.
+func ptyPipe(cmd *exec.Cmd, size ptySize, in io.Reader) (*ptyMaster, *os.File, error) {
+	master, slave, err := openPTY()
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := master.resize(size.rows, size.cols); err != nil {
+		master.Close()
+		slave.Close()
+		return nil, nil, err
+	}
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = slave, slave, slave
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	// a new session is also a new process group, and setpgid is not
+	// permitted for a session leader
+	cmd.SysProcAttr.Setpgid = false
+	cmd.SysProcAttr.Setsid = true
+	cmd.SysProcAttr.Setctty = true
+	cmd.SysProcAttr.Ctty = 0
+	if in != nil {
+		master.typeInto(in)
+	}
+	return master, slave, nil
+}
+
+func openPTY() (*ptyMaster, *os.File, error) {
+	f, err := os.OpenFile("/dev/ptmx", os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	var n uint32
+	err = ioctl(f, syscall.TIOCSPTLCK, unsafe.Pointer(&n))
+	if err == nil {
+		err = ioctl(f, syscall.TIOCGPTN, unsafe.Pointer(&n))
+	}
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	slave, err := os.OpenFile("/dev/pts/"+strconv.Itoa(int(n)), os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	return &ptyMaster{f: f}, slave, nil
+}
+
+func (m *ptyMaster) resize(rows, cols uint16) error {
+	ws := struct {
+		rows, cols, x, y uint16
+	}{rows, cols, 0, 0}
+	return ioctl(m.f, syscall.TIOCSWINSZ, unsafe.Pointer(&ws))
+}
+
+// ioctl calls ioctl on f without setting it to blocking mode as f.Fd().
+func ioctl(f *os.File, req uintptr, arg unsafe.Pointer) error {
+	conn, err := f.SyscallConn()
+	if err != nil {
+		return err
+	}
+	var errno syscall.Errno
+	err = conn.Control(func(fd uintptr) {
+		_, _, errno = syscall.Syscall(syscall.SYS_IOCTL, fd, req, uintptr(arg))
+	})
+	if err != nil {
+		return err
+	}
+	if errno != 0 {
+		return os.NewSyscallError("ioctl", errno)
+	}
+	return nil
+}
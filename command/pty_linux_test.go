@@ -0,0 +1,147 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/elvinchan/util-collects/as"
+)
+
+// syncBuffer is a bytes.Buffer safe for concurrent use.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestPTY(t *testing.T) {
+	t.Run("RunBytes", func(t *testing.T) {
+		b, err := RunBytes("tty", RunWithPTY(24, 80))
+		as.NoError(t, err)
+		as.Regexp(t, `^/dev/pts/\d+\r\n$`, string(b))
+
+		b, err = RunBytes("stty", RunWithArgs("size"), RunWithPTY(30, 100))
+		as.NoError(t, err)
+		as.Equal(t, string(b), "30 100\r\n")
+
+		b, err = RunBytes("sh", RunWithArgs("-c", "test -t 0 && test -t 1 && test -t 2 && echo tty 1>&2"),
+			RunWithPTY(24, 80))
+		as.NoError(t, err)
+		as.Equal(t, string(b), "tty\r\n")
+	})
+
+	t.Run("Stdin", func(t *testing.T) {
+		defer as.NoGoroutineLeak(t)()
+		b, err := RunBytes("cat", RunWithPTY(24, 80), RunWithStdinBytes([]byte("hello\n")),
+			RunWithTimeout(time.Second*5))
+		as.NoError(t, err)
+		// the input is echoed by terminal
+		as.Equal(t, strings.Count(string(b), "hello\r\n"), 2)
+
+		// EOF after the line not ended
+		b, err = RunBytes("cat", RunWithPTY(24, 80), RunWithStdinBytes([]byte("hello")),
+			RunWithTimeout(time.Second*2))
+		as.NoError(t, err)
+		as.Equal(t, strings.Count(string(b), "hello"), 2)
+	})
+
+	t.Run("Run", func(t *testing.T) {
+		res, err := Run("sh", RunWithArgs("-c", "echo hello; exit 3"), RunWithPTY(24, 80))
+		var exitErr *ExitError
+		as.ErrorAs(t, err, &exitErr)
+		as.Equal(t, string(exitErr.StderrTail), "hello")
+		as.Equal(t, res.ExitCode, 3)
+		as.Equal(t, string(res.Stdout), "hello\r\n")
+	})
+
+	t.Run("RunLines", func(t *testing.T) {
+		var lines []string
+		err := RunLines("sh", func(text string, stream Stream) error {
+			lines = append(lines, stream.String()+": "+text)
+			return nil
+		}, RunWithArgs("-c", "echo a; echo b 1>&2"), RunWithPTY(24, 80))
+		as.NoError(t, err)
+		as.Equal(t, lines, []string{"stdout: a", "stdout: b"})
+	})
+
+	t.Run("Interactive", func(t *testing.T) {
+		var out syncBuffer
+		p, err := StartInteractive("sh", StartWithArgs("-c", "read x; stty size; read x; stty size"),
+			StartWithPTY(24, 80), StartWithStdout(&out))
+		as.NoError(t, err)
+		_, err = io.WriteString(p.Input, "\n")
+		as.NoError(t, err)
+		as.Eventually(t, func(ctx context.Context) bool {
+			return strings.Contains(out.String(), "24 80\r\n")
+		}, time.Second*5, time.Millisecond*10)
+
+		as.NoError(t, p.Resize(40, 120))
+		_, err = io.WriteString(p.Input, "\n")
+		as.NoError(t, err)
+		as.NoError(t, p.Wait())
+		as.Contains(t, out.String(), "40 120\r\n")
+	})
+
+	t.Run("InteractiveEOF", func(t *testing.T) {
+		var out syncBuffer
+		p, err := StartInteractive("cat", StartWithPTY(24, 80), StartWithStdout(&out))
+		as.NoError(t, err)
+		_, err = io.WriteString(p.Input, "hello\n")
+		as.NoError(t, err)
+		as.NoError(t, p.Input.Close())
+		as.NoError(t, p.Wait())
+		as.Equal(t, strings.Count(out.String(), "hello\r\n"), 2)
+
+		out = syncBuffer{}
+		p, err = StartInteractive("cat", StartWithPTY(24, 80), StartWithStdout(&out))
+		as.NoError(t, err)
+		_, err = io.WriteString(p.Input, "hello")
+		as.NoError(t, err)
+		as.NoError(t, p.Input.Close())
+		as.NoError(t, p.Wait())
+		as.Equal(t, strings.Count(out.String(), "hello"), 2)
+	})
+
+	t.Run("CmdWait", func(t *testing.T) {
+		p, err := StartInteractive("true", StartWithPTY(24, 80))
+		as.NoError(t, err)
+		as.NoError(t, p.Cmd.Wait())
+		// terminal is closed once process is waited
+		as.Eventually(t, func(ctx context.Context) bool {
+			_, err := io.WriteString(p.Input, "\n")
+			return errors.Is(err, os.ErrClosed)
+		}, time.Second*5, time.Millisecond*10)
+	})
+
+	t.Run("Unsupported", func(t *testing.T) {
+		_, err := Start("tty", StartWithPTY(24, 80))
+		as.ErrorContains(t, err, "StartInteractive")
+
+		p, err := StartInteractive("cat")
+		as.NoError(t, err)
+		as.Error(t, p.Resize(24, 80))
+		as.NoError(t, p.Input.Close())
+		as.NoError(t, p.Wait())
+
+		_, err = Pipeline(NewStage("tty", RunWithPTY(24, 80))).Run()
+		as.Error(t, err)
+	})
+}
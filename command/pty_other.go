@@ -0,0 +1,21 @@
+//go:build !linux
+// +build !linux
+
+package command
+
+import (
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+)
+
+var errPTYNotSupported = errors.New("pseudo-terminal is only supported on linux")
+
+func ptyPipe(cmd *exec.Cmd, size ptySize, in io.Reader) (*ptyMaster, *os.File, error) {
+	return nil, nil, errPTYNotSupported
+}
+
+func (m *ptyMaster) resize(rows, cols uint16) error {
+	return errPTYNotSupported
+}
@@ -0,0 +1,193 @@
+package command
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Result is the result of a process run by Run.
+type Result struct {
+	// ExitCode is the exit code of process, or -1 if it was terminated by
+	// a signal.
+	ExitCode int
+	// Signal is the signal which terminated process, or nil if it exited.
+	Signal os.Signal
+	// Stdout and Stderr are the output of process, each limited by
+	// RunWithSize. Stderr is merged into Stdout by RunWithErrToOutput and
+	// RunWithPTY.
+	Stdout, Stderr []byte
+
+	StartTime, EndTime time.Time
+	Duration           time.Duration
+	// UserTime and SystemTime are the CPU time of process.
+	UserTime, SystemTime time.Duration
+	// MaxRSS is the maximum resident set size of process in bytes, which
+	// is always zero on windows.
+	MaxRSS int64
+}
+
+// setState sets the exit status and resource usage of result from ps.
+func (res *Result) setState(ps *os.ProcessState) {
+	if ps == nil {
+		return
+	}
+	res.ExitCode = ps.ExitCode()
+	if ws, ok := ps.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+		res.Signal = ws.Signal()
+	}
+	res.UserTime, res.SystemTime = ps.UserTime(), ps.SystemTime()
+	res.MaxRSS = maxRSS(ps)
+}
+
+// ExitError reports an unsuccessful exit of a process run by Run, with the
+// tail of its stderr.
+type ExitError struct {
+	*exec.ExitError
+	// StderrTail is the tail of stderr of process.
+	StderrTail []byte
+}
+
+func (e *ExitError) Error() string {
+	if len(e.StderrTail) == 0 {
+		return e.ExitError.Error()
+	}
+	return fmt.Sprintf("%s: %s", e.ExitError.Error(), e.StderrTail)
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.ExitError
+}
+
+// stderrTailSize is the max size of StderrTail of ExitError.
+const stderrTailSize = 512
+
+// Run runs command until process exit, and returns the result with exit
+// status, stdout and stderr separately, timings and resource usage.
+// If process exits unsuccessfully, the error is an *ExitError.
+func Run(name string, opts ...RunOption) (*Result, error) {
+	r, cancel := newRunner(opts)
+	defer cancel()
+	cmd := r.command(name)
+	var pipes []readPipe
+	var closeAfterStart []io.Closer
+	defer func() {
+		for _, p := range pipes {
+			p.Close()
+		}
+	}()
+	if r.pty != nil || r.errToOutput {
+		pr, pw, err := r.mergedPipe(cmd)
+		if err != nil {
+			return nil, err
+		}
+		pipes, closeAfterStart = []readPipe{pr}, []io.Closer{pw}
+	} else {
+		outReader, errReader, writers, err := separatePipes(cmd)
+		if err != nil {
+			return nil, err
+		}
+		pipes, closeAfterStart = []readPipe{outReader, errReader}, writers
+	}
+	res := &Result{
+		StartTime: time.Now(),
+	}
+	err := r.attr.start(cmd)
+	for _, c := range closeAfterStart {
+		c.Close()
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer r.watch(cmd)()
+
+	stdout := &limitBuffer{size: r.size}
+	stderr := &limitBuffer{size: r.size}
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	bufs := []*limitBuffer{stdout, stderr}
+	wg.Add(len(pipes))
+	for i, p := range pipes {
+		go copyTo(bufs[i], p, &wg)
+	}
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	err = waitAfter(r.ctx.Done(), cmd, done, pipes...)
+
+	res.EndTime = time.Now()
+	res.Duration = res.EndTime.Sub(res.StartTime)
+	res.Stdout, res.Stderr = stdout.Bytes(), stderr.Bytes()
+	res.setState(cmd.ProcessState)
+
+	if r.ctx.Err() != nil {
+		return res, r.ctx.Err()
+	}
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		errOutput := res.Stderr
+		if r.pty != nil || r.errToOutput {
+			errOutput = res.Stdout
+		}
+		return res, &ExitError{
+			ExitError:  exitErr,
+			StderrTail: tail(errOutput, stderrTailSize),
+		}
+	}
+	if err != nil {
+		return res, err
+	}
+	if stdout.beyond || stderr.beyond {
+		return res, beyondLimitError(r.size)
+	}
+	return res, nil
+}
+
+func copyTo(w io.Writer, r io.Reader, wg *sync.WaitGroup) {
+	defer wg.Done()
+	_, _ = io.Copy(w, r)
+}
+
+// tail returns the last lines of b within size, with spaces trimmed.
+func tail(b []byte, size int) []byte {
+	b = bytes.TrimSpace(b)
+	if len(b) <= size {
+		return b
+	}
+	b = b[len(b)-size:]
+	if i := bytes.IndexByte(b, '\n'); i >= 0 && i < len(b)-1 {
+		b = b[i+1:]
+	}
+	return b
+}
+
+// limitBuffer is a buffer keeps no more than size bytes written, and
+// discards the rest, so that the writer never blocks. Zero size means no
+// limit.
+type limitBuffer struct {
+	buf    bytes.Buffer
+	size   uint64
+	beyond bool
+}
+
+func (b *limitBuffer) Write(p []byte) (int, error) {
+	if b.size == 0 {
+		return b.buf.Write(p)
+	}
+	n := len(p)
+	if left := int(b.size) - b.buf.Len(); len(p) > left {
+		b.beyond = true
+		p = p[:left]
+	}
+	_, _ = b.buf.Write(p)
+	return n, nil
+}
+
+func (b *limitBuffer) Bytes() []byte {
+	return b.buf.Bytes()
+}
@@ -0,0 +1,81 @@
+//go:build !windows
+// +build !windows
+
+package command
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/elvinchan/util-collects/as"
+)
+
+func TestRun(t *testing.T) {
+	t.Run("Normal", func(t *testing.T) {
+		res, err := Run("sh", RunWithArgs("-c", "echo stdout; echo 1>&2 stderr"))
+		as.NoError(t, err)
+		as.Equal(t, res.ExitCode, 0)
+		as.Equal(t, res.Signal, nil)
+		as.Equal(t, string(res.Stdout), "stdout\n")
+		as.Equal(t, string(res.Stderr), "stderr\n")
+		as.Greater(t, res.Duration, time.Duration(0))
+		as.Equal(t, res.EndTime.Sub(res.StartTime), res.Duration)
+		as.Greater(t, res.MaxRSS, int64(0))
+	})
+
+	t.Run("ExitError", func(t *testing.T) {
+		res, err := Run("sh", RunWithArgs("-c", "echo 1>&2 first; echo 1>&2 bad thing; exit 3"))
+		as.Equal(t, res.ExitCode, 3)
+		var exitErr *ExitError
+		as.ErrorAs(t, err, &exitErr)
+		as.Equal(t, exitErr.ExitCode(), 3)
+		as.EqualError(t, err, "exit status 3: first\nbad thing")
+
+		var stdErr *exec.ExitError
+		as.True(t, errors.As(err, &stdErr))
+	})
+
+	t.Run("Signal", func(t *testing.T) {
+		res, err := Run("sh", RunWithArgs("-c", "kill -9 $$"))
+		as.Error(t, err)
+		as.Equal(t, res.ExitCode, -1)
+		as.Equal(t, res.Signal, syscall.SIGKILL)
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		start := time.Now()
+		res, err := Run("sh", RunWithArgs("-c", "echo start; sleep 2"),
+			RunWithTimeout(time.Millisecond*200))
+		as.ErrorIs(t, err, context.DeadlineExceeded)
+		as.Equal(t, string(res.Stdout), "start\n")
+		as.Less(t, time.Since(start), time.Second)
+	})
+
+	t.Run("ErrToOutput", func(t *testing.T) {
+		res, err := Run("sh", RunWithArgs("-c", "echo 1>&2 stderr; echo stdout"), RunWithErrToOutput())
+		as.NoError(t, err)
+		as.Equal(t, string(res.Stdout), "stderr\nstdout\n")
+		as.Empty(t, res.Stderr)
+	})
+
+	t.Run("TimeoutErrToOutput", func(t *testing.T) {
+		start := time.Now()
+		// the merged pipe is still held by sleep after sh killed
+		res, err := Run("sh", RunWithArgs("-c", "echo start; sleep 3; true"),
+			RunWithErrToOutput(), RunWithTimeout(time.Millisecond*200))
+		as.ErrorIs(t, err, context.DeadlineExceeded)
+		as.Equal(t, string(res.Stdout), "start\n")
+		as.Less(t, time.Since(start), time.Second)
+	})
+
+	t.Run("BeyondLimit", func(t *testing.T) {
+		res, err := Run("sh", RunWithArgs("-c", "echo abcdefgh; echo 1>&2 ab"), RunWithSize(4))
+		as.ErrorContains(t, err, "data beyond limit")
+		as.Equal(t, string(res.Stdout), "abcd")
+		as.Equal(t, string(res.Stderr), "ab\n")
+	})
+}
@@ -0,0 +1,23 @@
+//go:build !windows
+// +build !windows
+
+package command
+
+import (
+	"os"
+	"runtime"
+	"syscall"
+)
+
+func maxRSS(ps *os.ProcessState) int64 {
+	ru, ok := ps.SysUsage().(*syscall.Rusage)
+	if !ok {
+		return 0
+	}
+	if runtime.GOOS == "darwin" || runtime.GOOS == "ios" {
+		// in bytes
+		return int64(ru.Maxrss)
+	}
+	// in kilobytes
+	return int64(ru.Maxrss) * 1024
+}
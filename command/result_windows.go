@@ -0,0 +1,7 @@
+package command
+
+import "os"
+
+func maxRSS(ps *os.ProcessState) int64 {
+	return 0
+}
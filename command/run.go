@@ -6,7 +6,9 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"os"
 	"os/exec"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -15,10 +17,16 @@ import (
 
 type Runner struct {
 	ctx         context.Context
-	args, envs  []string
+	args        []string
+	env         environ
+	attr        procAttr
+	stdin       io.Reader
 	timeout     time.Duration
+	stop        *gracefulStop
 	size        uint64
 	errToOutput bool
+	timestamp   bool
+	pty         *ptySize
 }
 
 type RunOption func(*Runner)
@@ -35,9 +43,98 @@ func RunWithArgs(args ...string) RunOption {
 	}
 }
 
+// RunWithEnv specified environment variables like "KEY=value", which
+// override the ones inherited from current process.
 func RunWithEnv(envs ...string) RunOption {
 	return func(r *Runner) {
-		r.envs = append(r.envs, envs...)
+		r.env.add(envs...)
+	}
+}
+
+// RunWithEnvMap is like RunWithEnv, which variables are added in the order
+// of keys.
+func RunWithEnvMap(envs map[string]string) RunOption {
+	return func(r *Runner) {
+		r.env.addMap(envs)
+	}
+}
+
+// RunWithCleanEnv specified that no environment variable is inherited from
+// current process, only the ones specified by RunWithEnv are set.
+func RunWithCleanEnv() RunOption {
+	return func(r *Runner) {
+		r.env.clean = true
+	}
+}
+
+// RunWithEnvAllow specified that only the environment variables of keys
+// are inherited from current process. A key ending with "*" matches
+// variables with the prefix, e.g. "LC_*".
+func RunWithEnvAllow(keys ...string) RunOption {
+	return func(r *Runner) {
+		r.env.allow = append(r.env.allow, keys...)
+	}
+}
+
+// RunWithEnvDeny specified that the environment variables of keys are not
+// inherited from current process. A key ending with "*" matches variables
+// with the prefix, e.g. "AWS_*".
+func RunWithEnvDeny(keys ...string) RunOption {
+	return func(r *Runner) {
+		r.env.deny = append(r.env.deny, keys...)
+	}
+}
+
+// RunWithStdin specified the standard input of process. If in is not an
+// *os.File, it is copied by a goroutine, which is waited for by the run,
+// so in should reach EOF or return an error eventually.
+func RunWithStdin(in io.Reader) RunOption {
+	return func(r *Runner) {
+		r.stdin = in
+	}
+}
+
+func RunWithStdinBytes(in []byte) RunOption {
+	return RunWithStdin(bytes.NewReader(in))
+}
+
+// RunWithDir specified the working directory of process, defaults to the
+// one of current process.
+func RunWithDir(dir string) RunOption {
+	return func(r *Runner) {
+		r.attr.dir = dir
+	}
+}
+
+// RunWithCredential specified the user and groups which process runs as,
+// not supported on windows.
+func RunWithCredential(uid, gid uint32, groups ...uint32) RunOption {
+	return func(r *Runner) {
+		r.attr.cred = &credential{uid: uid, gid: gid, groups: groups}
+	}
+}
+
+// RunWithNice specified the niceness of process, not supported on windows.
+func RunWithNice(nice int) RunOption {
+	return func(r *Runner) {
+		r.attr.nice = &nice
+	}
+}
+
+// RunWithLimits specified the resource limits of process, only supported on
+// linux.
+func RunWithLimits(limits Limits) RunOption {
+	return func(r *Runner) {
+		r.attr.limits = limits
+	}
+}
+
+// RunWithLimitsAfterStart specified that the niceness and limits of process
+// are applied right after process started instead of before exec, which
+// does not require ptrace, but process may run before they take effect.
+func RunWithLimitsAfterStart() RunOption {
+	return func(r *Runner) {
+		r.attr.afterStart = true
 	}
 }
 
@@ -59,44 +156,108 @@ func RunWithErrToOutput() RunOption {
 	}
 }
 
-// RunBytes runs command and receives byte slice from stdout until
-// process exit or any error when reading from stdout.
-//
-// refer: https://medium.com/@vCabbage/go-timeout-commands-with-os-exec-commandcontext-ba0c861ed738
-func RunBytes(name string, opts ...RunOption) ([]byte, error) {
+// RunWithPTY runs process in a new pseudo-terminal of rows and cols, which
+// output is read as stdout, with stderr merged and the input typed echoed.
+// The stdin of RunWithStdin is typed into terminal followed by EOF (Ctrl-D).
+// Only supported on linux.
+func RunWithPTY(rows, cols uint16) RunOption {
+	return func(r *Runner) {
+		r.pty = &ptySize{rows: rows, cols: cols}
+	}
+}
+
+// RunWithTimestamp tags each chunk of output read by RunBytes with the time
+// it is read, e.g. "[2006-01-02T15:04:05.000000Z07:00] chunk".
+func RunWithTimestamp() RunOption {
+	return func(r *Runner) {
+		r.timestamp = true
+	}
+}
+
+func newRunner(opts []RunOption) (*Runner, context.CancelFunc) {
 	r := &Runner{
 		ctx: context.Background(),
 	}
 	for _, opt := range opts {
 		opt(r)
 	}
+	cancel := func() {}
 	if r.timeout > 0 {
-		var cancel context.CancelFunc
 		r.ctx, cancel = context.WithTimeout(r.ctx, r.timeout)
-		defer cancel()
 	}
-	cmd := exec.CommandContext(r.ctx, name, r.args...)
-	cmd.Env = r.envs
-	outReader, err := cmd.StdoutPipe()
-	if err != nil {
-		return nil, err
+	return r, cancel
+}
+
+func (r *Runner) command(name string) *exec.Cmd {
+	var cmd *exec.Cmd
+	if r.stop != nil {
+		// stopped by watch instead
+		cmd = exec.Command(name, r.args...)
+	} else {
+		cmd = exec.CommandContext(r.ctx, name, r.args...)
+	}
+	cmd.Env = r.env.build()
+	cmd.Dir = r.attr.dir
+	cmd.Stdin = r.stdin
+	return cmd
+}
+
+// watch stops the started process of cmd gracefully when context is done,
+// if specified by RunWithGracefulStop. The returned function should be
+// called after process exit.
+func (r *Runner) watch(cmd *exec.Cmd) (release func()) {
+	if r.stop == nil {
+		return func() {}
 	}
+	release, _ = r.stop.watch(r.ctx, []*os.Process{cmd.Process}, false)
+	return release
+}
+
+// RunBytes runs command and receives byte slice from stdout until
+// process exit or any error when reading from stdout.
+//
+// refer: https://medium.com/@vCabbage/go-timeout-commands-with-os-exec-commandcontext-ba0c861ed738
+func RunBytes(name string, opts ...RunOption) ([]byte, error) {
+	r, cancel := newRunner(opts)
+	defer cancel()
+	cmd := r.command(name)
 	var stdout io.Reader
-	if r.errToOutput {
-		errReader, err := cmd.StderrPipe()
+	var pr io.ReadCloser
+	if r.pty != nil || r.errToOutput {
+		var pw io.Closer
+		var err error
+		if pr, pw, err = r.mergedPipe(cmd); err != nil {
+			return nil, err
+		}
+		err = r.attr.start(cmd)
+		pw.Close()
 		if err != nil {
+			pr.Close()
 			return nil, err
 		}
-		stdout = io.MultiReader(outReader, errReader)
+		stdout = pr
 	} else {
+		outReader, err := cmd.StdoutPipe()
+		if err != nil {
+			return nil, err
+		}
+		if err := r.attr.start(cmd); err != nil {
+			return nil, err
+		}
 		stdout = outReader
 	}
-	if err := cmd.Start(); err != nil {
-		return nil, err
+	if r.timestamp {
+		stdout = &timestampReader{r: stdout}
 	}
+	defer r.watch(cmd)()
 
 	var hadWait uint32
 	defer func() {
+		// the merged pipe is not closed by Wait, close it first so that
+		// process writing to it would not be blocked
+		if pr != nil {
+			pr.Close()
+		}
 		// prevent two approach:
 		// panic when reading from pipe
 		// read from pipe with error
@@ -122,12 +283,92 @@ func RunBytes(name string, opts ...RunOption) ([]byte, error) {
 
 	select {
 	case <-r.ctx.Done():
+		if r.stop != nil {
+			// wait for process being stopped
+			<-errc
+		}
 		return data, r.ctx.Err()
 	case err := <-errc:
 		return data, err
 	}
 }
 
+// combinedPipe returns a pipe which both stdout and stderr of cmd write to,
+// so that the output of them is merged in the order it is written, and
+// neither of them blocks for the other not being read. The writer should be
+// closed after cmd started.
+func combinedPipe(cmd *exec.Cmd) (pr, pw *os.File, err error) {
+	pr, pw, err = os.Pipe()
+	if err != nil {
+		return nil, nil, err
+	}
+	cmd.Stdout = pw
+	cmd.Stderr = pw
+	return pr, pw, nil
+}
+
+// readPipe is the reader of output of process, which is not closed by Wait
+// unlike StdoutPipe, so that the output left is read after process exit.
+type readPipe interface {
+	io.ReadCloser
+	SetReadDeadline(t time.Time) error
+}
+
+// separatePipes returns pipes which stdout and stderr of cmd write to
+// respectively. The writers should be closed after cmd started.
+func separatePipes(cmd *exec.Cmd) (outReader, errReader readPipe, writers []io.Closer, err error) {
+	outPR, outPW, err := os.Pipe()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	errPR, errPW, err := os.Pipe()
+	if err != nil {
+		outPR.Close()
+		outPW.Close()
+		return nil, nil, nil, err
+	}
+	cmd.Stdout, cmd.Stderr = outPW, errPW
+	return outPR, errPR, []io.Closer{outPW, errPW}, nil
+}
+
+// mergedPipe returns a reader of both stdout and stderr of cmd, which is a
+// pseudo-terminal if RunWithPTY, or a pipe by combinedPipe. The writer
+// should be closed after cmd started.
+func (r *Runner) mergedPipe(cmd *exec.Cmd) (readPipe, io.Closer, error) {
+	if r.pty != nil {
+		return ptyPipe(cmd, *r.pty, r.stdin)
+	}
+	return combinedPipe(cmd)
+}
+
+// timestampReader tags each chunk read from r with the time it is read.
+type timestampReader struct {
+	r       io.Reader
+	buf     []byte
+	pending []byte
+}
+
+const timestampLayout = "2006-01-02T15:04:05.000000Z07:00"
+
+func (t *timestampReader) Read(p []byte) (int, error) {
+	if len(t.pending) == 0 {
+		if t.buf == nil {
+			t.buf = make([]byte, 32*1024)
+		}
+		n, err := t.r.Read(t.buf)
+		if n == 0 {
+			return 0, err
+		}
+		t.pending = append(t.pending[:0], '[')
+		t.pending = time.Now().AppendFormat(t.pending, timestampLayout)
+		t.pending = append(t.pending, "] "...)
+		t.pending = append(t.pending, t.buf[:n]...)
+	}
+	n := copy(p, t.pending)
+	t.pending = t.pending[n:]
+	return n, nil
+}
+
 func readLimit(r io.Reader, size uint64) ([]byte, error) {
 	if size == 0 {
 		// read all
@@ -137,7 +378,46 @@ func readLimit(r io.Reader, size uint64) ([]byte, error) {
 	buf.Grow(1024) // default 1KB
 	n, err := buf.ReadFrom(&io.LimitedReader{R: r, N: int64(size + 1)})
 	if n > int64(size) {
-		return buf.Bytes()[:size], fmt.Errorf("data beyond limit: %v", human.IBytes(size))
+		return buf.Bytes()[:size], beyondLimitError(size)
 	}
 	return buf.Bytes(), err
 }
+
+func beyondLimitError(size uint64) error {
+	return fmt.Errorf("data beyond limit: %v", human.IBytes(size))
+}
+
+// drainTimeout is the time limit of reading the output left in pipes after
+// process exited, once context is done.
+const drainTimeout = time.Millisecond * 100
+
+// waitAfter waits for cmd after done is closed, which means all reads from
+// the pipes of cmd are completed. Once stopping is closed, i.e. context is
+// done, the process is being stopped, but the pipes may be still held by its
+// descendants after it exited. So wait for the process in parallel, and the
+// output left in pipes is read until drainTimeout after it exited, that
+// reads are unblocked.
+func waitAfter(stopping <-chan struct{}, cmd *exec.Cmd, done <-chan struct{}, pipes ...readPipe) error {
+	var (
+		once sync.Once
+		err  error
+	)
+	wait := func() { err = cmd.Wait() }
+	select {
+	case <-done:
+	case <-stopping:
+		go once.Do(func() {
+			wait()
+			deadline := time.Now().Add(drainTimeout)
+			for _, p := range pipes {
+				if p.SetReadDeadline(deadline) != nil {
+					// not supported, e.g. pipes on windows
+					p.Close()
+				}
+			}
+		})
+		<-done
+	}
+	once.Do(wait)
+	return err
+}
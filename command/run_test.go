@@ -3,6 +3,7 @@ package command
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -38,5 +39,41 @@ func TestRunBytes(t *testing.T) {
 		b, err = RunBytes("sh", RunWithArgs("-c", "echo stdout; echo 1>&2 stderr"), RunWithErrToOutput())
 		as.NoError(t, err)
 		as.Equal(t, string(b), "stdout\nstderr\n")
+
+		b, err = RunBytes("sh", RunWithArgs("-c", "echo 1>&2 stderr; echo stdout; echo 1>&2 stderr"), RunWithErrToOutput())
+		as.NoError(t, err)
+		as.Equal(t, string(b), "stderr\nstdout\nstderr\n")
+	})
+
+	t.Run("ErrToOutputFullPipe", func(t *testing.T) {
+		// stderr beyond the capacity of pipe should not block stdout
+		b, err := RunBytes("sh", RunWithArgs("-c", "head -c 200000 /dev/zero 1>&2; echo stdout"),
+			RunWithErrToOutput(), RunWithTimeout(time.Second*5))
+		as.NoError(t, err)
+		as.Len(t, b, 200000+len("stdout\n"))
+
+		b, err = RunBytes("sh", RunWithArgs("-c", "head -c 200000 /dev/zero 1>&2; echo stdout"),
+			RunWithErrToOutput(), RunWithSize(1024))
+		as.ErrorContains(t, err, "data beyond limit")
+		as.Len(t, b, 1024)
+	})
+
+	t.Run("Stdin", func(t *testing.T) {
+		b, err := RunBytes("cat", RunWithStdin(strings.NewReader("hello\n")))
+		as.NoError(t, err)
+		as.Equal(t, string(b), "hello\n")
+
+		b, err = RunBytes("sh", RunWithStdinBytes([]byte("echo hello; echo 1>&2 world")),
+			RunWithErrToOutput())
+		as.NoError(t, err)
+		as.Equal(t, string(b), "hello\nworld\n")
+	})
+
+	t.Run("Timestamp", func(t *testing.T) {
+		b, err := RunBytes("sh", RunWithArgs("-c", "echo stdout; sleep 0.1; echo 1>&2 stderr"),
+			RunWithErrToOutput(), RunWithTimestamp())
+		as.NoError(t, err)
+		as.Regexp(t, `^\[\d{4}-\d\d-\d\dT[\d:.]+(Z|[+-]\d\d:\d\d)\] stdout\n`+
+			`\[\d{4}-\d\d-\d\dT[\d:.]+(Z|[+-]\d\d:\d\d)\] stderr\n$`, string(b))
 	})
 }
@@ -2,16 +2,25 @@ package command
 
 import (
 	"context"
+	"errors"
 	"io"
+	"io/ioutil"
+	"os"
 	"os/exec"
+	"sync"
 	"syscall"
 )
 
 type Starter struct {
-	ctx        context.Context
-	args, envs []string
-	out, err   io.Writer
-	detach     bool
+	ctx      context.Context
+	args     []string
+	env      environ
+	attr     procAttr
+	in       io.Reader
+	out, err io.Writer
+	detach   bool
+	stop     *gracefulStop
+	pty      *ptySize
 }
 
 type StartOption func(*Starter)
@@ -28,9 +37,93 @@ func StartWithArgs(args ...string) StartOption {
 	}
 }
 
+// StartWithEnv specified environment variables like "KEY=value", which
+// override the ones inherited from current process.
 func StartWithEnv(envs ...string) StartOption {
 	return func(s *Starter) {
-		s.envs = append(s.envs, envs...)
+		s.env.add(envs...)
+	}
+}
+
+// StartWithEnvMap is like StartWithEnv, which variables are added in the
+// order of keys.
+func StartWithEnvMap(envs map[string]string) StartOption {
+	return func(s *Starter) {
+		s.env.addMap(envs)
+	}
+}
+
+// StartWithCleanEnv specified that no environment variable is inherited
+// from current process, only the ones specified by StartWithEnv are set.
+func StartWithCleanEnv() StartOption {
+	return func(s *Starter) {
+		s.env.clean = true
+	}
+}
+
+// StartWithEnvAllow specified that only the environment variables of keys
+// are inherited from current process. A key ending with "*" matches
+// variables with the prefix, e.g. "LC_*".
+func StartWithEnvAllow(keys ...string) StartOption {
+	return func(s *Starter) {
+		s.env.allow = append(s.env.allow, keys...)
+	}
+}
+
+// StartWithEnvDeny specified that the environment variables of keys are not
+// inherited from current process. A key ending with "*" matches variables
+// with the prefix, e.g. "AWS_*".
+func StartWithEnvDeny(keys ...string) StartOption {
+	return func(s *Starter) {
+		s.env.deny = append(s.env.deny, keys...)
+	}
+}
+
+func StartWithStdin(in io.Reader) StartOption {
+	return func(s *Starter) {
+		s.in = in
+	}
+}
+
+// StartWithDir specified the working directory of process, defaults to the
+// one of current process.
+func StartWithDir(dir string) StartOption {
+	return func(s *Starter) {
+		s.attr.dir = dir
+	}
+}
+
+// StartWithCredential specified the user and groups which process runs as,
+// not supported on windows.
+func StartWithCredential(uid, gid uint32, groups ...uint32) StartOption {
+	return func(s *Starter) {
+		s.attr.cred = &credential{uid: uid, gid: gid, groups: groups}
+	}
+}
+
+// StartWithNice specified the niceness of process, not supported on
+// windows.
+func StartWithNice(nice int) StartOption {
+	return func(s *Starter) {
+		s.attr.nice = &nice
+	}
+}
+
+// StartWithLimits specified the resource limits of process, only supported
+// on linux.
+func StartWithLimits(limits Limits) StartOption {
+	return func(s *Starter) {
+		s.attr.limits = limits
+	}
+}
+
+// StartWithLimitsAfterStart specified that the niceness and limits of
+// process are applied right after process started instead of before exec,
+// which does not require ptrace, but process may run before they take
+// effect.
+func StartWithLimitsAfterStart() StartOption {
+	return func(s *Starter) {
+		s.attr.afterStart = true
 	}
 }
 
@@ -46,21 +139,140 @@ func StartWithStderr(err io.Writer) StartOption {
 	}
 }
 
+// StartWithPTY starts process in a new pseudo-terminal of rows and cols by
+// StartInteractive, which output is written to the writer of
+// StartWithStdout, with stderr merged and the input typed echoed. Process
+// is the leader of a new session, which is also a new process group like
+// StartWithDetach. Only supported on linux.
+func StartWithPTY(rows, cols uint16) StartOption {
+	return func(s *Starter) {
+		s.pty = &ptySize{rows: rows, cols: cols}
+	}
+}
+
 func StartWithDetach() StartOption {
 	return func(s *Starter) {
 		s.detach = true
 	}
 }
 
+// Start starts process in background, which should be waited by Wait of the
+// returned cmd. With StartWithGracefulStop, the watcher of context is held
+// until process is waited, or context is done.
 func Start(name string, opts ...StartOption) (*exec.Cmd, error) {
+	s := newStarter(opts)
+	cmd, release, err := s.start(name)
+	if release != nil {
+		go releaseAfterWait(cmd.Process, release, s.ctx.Done())
+	}
+	return cmd, err
+}
+
+// start is like Start, and returns the function releasing the watcher of
+// graceful stop if any, which should be called after process is waited.
+func (s *Starter) start(name string) (*exec.Cmd, func(), error) {
+	cmd := s.command(name)
+	if s.pty != nil {
+		return cmd, nil, errors.New("pseudo-terminal is only supported by StartInteractive")
+	}
+	if err := s.attr.start(cmd); err != nil {
+		return cmd, nil, err
+	}
+	return cmd, s.watch(cmd), nil
+}
+
+// Process is a started process with a writable standard input, for driving
+// interactive programs like sh, psql or openssl.
+type Process struct {
+	*exec.Cmd
+
+	// Input is connected to the standard input of process, close it to
+	// send EOF. It is closed by Wait after process exit, and by Cmd.Wait
+	// as well if StartWithPTY.
+	Input io.WriteCloser
+
+	// release releases the watcher of graceful stop, which may be nil.
+	release     func()
+	pty         *ptyMaster
+	copied      chan struct{}
+	cleanupOnce sync.Once
+}
+
+// Resize resizes the window of pseudo-terminal of process started with
+// StartWithPTY, and process is notified by SIGWINCH.
+func (p *Process) Resize(rows, cols uint16) error {
+	if p.pty == nil {
+		return errors.New("process is not started with pseudo-terminal")
+	}
+	return p.pty.resize(rows, cols)
+}
+
+// Wait waits for process to exit like exec.Cmd, and also for the output of
+// pseudo-terminal to be copied if StartWithPTY. It releases the watcher of
+// StartWithGracefulStop, which is otherwise held until context is done.
+func (p *Process) Wait() error {
+	err := p.Cmd.Wait()
+	p.cleanup()
+	return err
+}
+
+// cleanup releases the watcher of graceful stop, and closes pseudo-terminal
+// after its output copied. It must be called after process is waited, since
+// closing pseudo-terminal hangs up process.
+func (p *Process) cleanup() {
+	if p.pty != nil {
+		<-p.copied
+	}
+	p.cleanupOnce.Do(func() {
+		if p.release != nil {
+			p.release()
+		}
+		if p.pty != nil {
+			p.pty.Close()
+		}
+	})
+}
+
+// StartInteractive starts process like Start, with a pipe connected to its
+// standard input instead of StartWithStdin, or a pseudo-terminal if
+// StartWithPTY, which input is typed into terminal.
+func StartInteractive(name string, opts ...StartOption) (*Process, error) {
+	s := newStarter(opts)
+	cmd := s.command(name)
+	if s.pty != nil {
+		return s.startPTY(cmd)
+	}
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.attr.start(cmd); err != nil {
+		return nil, err
+	}
+	return &Process{Cmd: cmd, Input: stdin, release: s.watch(cmd)}, nil
+}
+
+func newStarter(opts []StartOption) *Starter {
 	s := &Starter{
 		ctx: context.Background(),
 	}
 	for _, opt := range opts {
 		opt(s)
 	}
-	cmd := exec.CommandContext(s.ctx, name, s.args...)
-	cmd.Env = s.envs
+	return s
+}
+
+func (s *Starter) command(name string) *exec.Cmd {
+	var cmd *exec.Cmd
+	if s.stop != nil {
+		// stopped by watch instead
+		cmd = exec.Command(name, s.args...)
+	} else {
+		cmd = exec.CommandContext(s.ctx, name, s.args...)
+	}
+	cmd.Env = s.env.build()
+	cmd.Dir = s.attr.dir
+	cmd.Stdin = s.in
 	cmd.Stdout = s.out
 	cmd.Stderr = s.err
 	if s.detach {
@@ -69,5 +281,52 @@ func Start(name string, opts ...StartOption) (*exec.Cmd, error) {
 		}
 		detachAttr(cmd.SysProcAttr)
 	}
-	return cmd, cmd.Start()
+	return cmd
+}
+
+// watch stops the started process of cmd gracefully when context is done,
+// if specified by StartWithGracefulStop, until the returned function is
+// called. It returns nil if process is not watched.
+func (s *Starter) watch(cmd *exec.Cmd) (release func()) {
+	if s.stop == nil || s.ctx.Done() == nil {
+		return nil
+	}
+	release, _ = s.stop.watch(s.ctx, []*os.Process{cmd.Process}, s.detach)
+	return release
+}
+
+func (s *Starter) startPTY(cmd *exec.Cmd) (*Process, error) {
+	if s.in != nil {
+		return nil, errors.New("exec: Stdin already set")
+	}
+	master, slave, err := ptyPipe(cmd, *s.pty, nil)
+	if err != nil {
+		return nil, err
+	}
+	err = s.attr.start(cmd)
+	slave.Close()
+	if err != nil {
+		master.Close()
+		return nil, err
+	}
+
+	out := s.out
+	if out == nil {
+		out = ioutil.Discard
+	}
+	copied := make(chan struct{})
+	go func() {
+		defer close(copied)
+		_, _ = io.Copy(out, master)
+	}()
+	p := &Process{
+		Cmd:     cmd,
+		Input:   &ptyInput{m: master},
+		release: s.watch(cmd),
+		pty:     master,
+		copied:  copied,
+	}
+	// closed also if process is waited by Cmd.Wait instead of Wait
+	go releaseAfterWait(cmd.Process, p.cleanup, nil)
+	return p, nil
 }
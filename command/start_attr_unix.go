@@ -2,8 +2,25 @@
 
 package command
 
-import "syscall"
+import (
+	"os"
+	"syscall"
+)
 
 func detachAttr(attr *syscall.SysProcAttr) {
 	attr.Setpgid = true
 }
+
+// signalProcess sends sig to p, or the process group led by p if group.
+func signalProcess(p *os.Process, sig os.Signal, group bool) error {
+	s, ok := sig.(syscall.Signal)
+	if !group || !ok {
+		return p.Signal(sig)
+	}
+	err := syscall.Kill(-p.Pid, s)
+	if err == syscall.ESRCH {
+		// no process left in the group
+		return os.ErrProcessDone
+	}
+	return err
+}
@@ -1,7 +1,16 @@
 package command
 
-import "syscall"
+import (
+	"os"
+	"syscall"
+)
 
 func detachAttr(attr *syscall.SysProcAttr) {
 	attr.CreationFlags = syscall.CREATE_NEW_PROCESS_GROUP
 }
+
+// signalProcess sends sig to p. Signals cannot be sent to process group on
+// windows, so group is ignored.
+func signalProcess(p *os.Process, sig os.Signal, group bool) error {
+	return p.Signal(sig)
+}
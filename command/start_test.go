@@ -5,6 +5,8 @@ package command
 
 import (
 	"bytes"
+	"io"
+	"strings"
 	"syscall"
 	"testing"
 	"time"
@@ -38,4 +40,28 @@ func TestStart(t *testing.T) {
 		time.Sleep(time.Millisecond * 500)
 		as.Equal(t, buf.String(), "hello\n")
 	})
+	t.Run("Stdin", func(t *testing.T) {
+		var buf bytes.Buffer
+		p, err := Start("cat", StartWithStdin(strings.NewReader("hello\n")), StartWithStdout(&buf))
+		as.NoError(t, err)
+		as.NoError(t, p.Wait())
+		as.Equal(t, buf.String(), "hello\n")
+	})
+}
+
+func TestStartInteractive(t *testing.T) {
+	var buf bytes.Buffer
+	p, err := StartInteractive("sh", StartWithStdout(&buf))
+	as.NoError(t, err)
+	_, err = io.WriteString(p.Input, "echo hello\n")
+	as.NoError(t, err)
+	_, err = io.WriteString(p.Input, "exit 3\n")
+	as.NoError(t, err)
+	err = p.Wait()
+	as.Error(t, err)
+	as.Equal(t, p.ProcessState.ExitCode(), 3)
+	as.Equal(t, buf.String(), "hello\n")
+
+	_, err = StartInteractive("cat", StartWithStdin(strings.NewReader("")))
+	as.ErrorContains(t, err, "Stdin already set")
 }
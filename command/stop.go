@@ -0,0 +1,98 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// waitPollInterval is the interval of polling whether process is waited.
+const waitPollInterval = time.Millisecond * 50
+
+// gracefulStop specified how to stop process when context is done, instead
+// of killing it immediately.
+type gracefulStop struct {
+	sig   os.Signal
+	grace time.Duration
+}
+
+// RunWithGracefulStop specified that when context is done, sig (e.g.
+// SIGTERM) is sent to process first, and it is killed if still running
+// after grace period.
+func RunWithGracefulStop(sig os.Signal, grace time.Duration) RunOption {
+	return func(r *Runner) {
+		r.stop = &gracefulStop{sig: sig, grace: grace}
+	}
+}
+
+// StartWithGracefulStop specified that when context is done, sig (e.g.
+// SIGTERM) is sent to process first, and it is killed if still running
+// after grace period. With StartWithDetach, signals are sent to the process
+// group, so that the descendants of process are stopped too.
+func StartWithGracefulStop(sig os.Signal, grace time.Duration) StartOption {
+	return func(s *Starter) {
+		s.stop = &gracefulStop{sig: sig, grace: grace}
+	}
+}
+
+// watch stops processes gracefully when ctx is done, until the returned
+// function is called, which should be done after processes exit. The
+// returned channel is closed once processes are killed after grace period.
+func (g *gracefulStop) watch(ctx context.Context, ps []*os.Process,
+	group bool) (release func(), killed <-chan struct{}) {
+	released := make(chan struct{})
+	kill := make(chan struct{})
+	go func() {
+		select {
+		case <-released:
+			return
+		case <-ctx.Done():
+		}
+		running := false
+		for _, p := range ps {
+			err := signalProcess(p, g.sig, group)
+			running = running || !errors.Is(err, os.ErrProcessDone)
+		}
+		if !running {
+			return
+		}
+		timer := time.NewTimer(g.grace)
+		defer timer.Stop()
+		select {
+		case <-released:
+		case <-timer.C:
+			for _, p := range ps {
+				_ = signalProcess(p, os.Kill, group)
+			}
+			close(kill)
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(released) })
+	}, kill
+}
+
+// releaseAfterWait calls release once p is waited by exec.Cmd, which is
+// polled since it is not notified otherwise. Polling stops without calling
+// release once stop is closed, e.g. context is done, after which the
+// watcher of graceful stop exits by itself.
+func releaseAfterWait(p *os.Process, release func(), stop <-chan struct{}) {
+	ticker := time.NewTicker(waitPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+		// signal 0 only checks, and fails with ErrProcessDone once waited
+		if errors.Is(p.Signal(syscall.Signal(0)), os.ErrProcessDone) {
+			release()
+			return
+		}
+	}
+}
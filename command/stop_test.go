@@ -0,0 +1,131 @@
+//go:build !windows
+// +build !windows
+
+package command
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/elvinchan/util-collects/as"
+)
+
+func TestGracefulStop(t *testing.T) {
+	t.Run("Signal", func(t *testing.T) {
+		res, err := Run("sh", RunWithArgs("-c", "trap 'echo term; exit 0' TERM; "+
+			"echo ready; while :; do sleep 0.01; done"),
+			RunWithTimeout(time.Millisecond*200),
+			RunWithGracefulStop(syscall.SIGTERM, time.Second*5))
+		as.ErrorIs(t, err, context.DeadlineExceeded)
+		as.Equal(t, string(res.Stdout), "ready\nterm\n")
+		as.Equal(t, res.ExitCode, 0)
+	})
+
+	t.Run("Escalate", func(t *testing.T) {
+		res, err := Run("sh", RunWithArgs("-c", "trap '' TERM; "+
+			"echo ready; while :; do sleep 0.01; done"),
+			RunWithTimeout(time.Millisecond*200),
+			RunWithGracefulStop(syscall.SIGTERM, time.Millisecond*200))
+		as.ErrorIs(t, err, context.DeadlineExceeded)
+		as.Equal(t, string(res.Stdout), "ready\n")
+		as.Equal(t, res.Signal, syscall.SIGKILL)
+		as.GreaterOrEqual(t, res.Duration, time.Millisecond*400)
+	})
+
+	t.Run("RunBytes", func(t *testing.T) {
+		b, err := RunBytes("sh", RunWithArgs("-c", "trap 'echo term; exit 0' TERM; "+
+			"echo ready; while :; do sleep 0.01; done"),
+			RunWithTimeout(time.Millisecond*200),
+			RunWithGracefulStop(syscall.SIGTERM, time.Second*5))
+		as.ErrorIs(t, err, context.DeadlineExceeded)
+		as.Equal(t, string(b), "ready\nterm\n")
+	})
+
+	t.Run("Descendant", func(t *testing.T) {
+		// the pipes are still held by sleep after sh exited on SIGTERM
+		args := RunWithArgs("-c", "trap 'echo term; exit 0' TERM; echo ready; sleep 3 & wait")
+		start := time.Now()
+		res, err := Run("sh", args, RunWithTimeout(time.Millisecond*200),
+			RunWithGracefulStop(syscall.SIGTERM, time.Second*10))
+		as.ErrorIs(t, err, context.DeadlineExceeded)
+		as.Equal(t, string(res.Stdout), "ready\nterm\n")
+		as.Equal(t, res.ExitCode, 0)
+		as.Less(t, time.Since(start), time.Second)
+
+		start = time.Now()
+		var lines []string
+		err = RunLines("sh", func(text string, stream Stream) error {
+			lines = append(lines, text)
+			return nil
+		}, args, RunWithTimeout(time.Millisecond*200),
+			RunWithGracefulStop(syscall.SIGTERM, time.Second*10))
+		as.ErrorIs(t, err, context.DeadlineExceeded)
+		as.Equal(t, lines, []string{"ready", "term"})
+		as.Less(t, time.Since(start), time.Second)
+	})
+
+	t.Run("Release", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		// watchers exit after processes waited, though ctx is not done
+		defer as.NoGoroutineLeak(t)()
+		for i := 0; i < 10; i++ {
+			p, err := Start("true", StartWithContext(ctx), StartWithDetach(),
+				StartWithGracefulStop(syscall.SIGTERM, time.Second))
+			as.NoError(t, err)
+			as.NoError(t, p.Wait())
+		}
+		for i := 0; i < 10; i++ {
+			p, err := StartInteractive("true", StartWithContext(ctx),
+				StartWithGracefulStop(syscall.SIGTERM, time.Second))
+			as.NoError(t, err)
+			as.NoError(t, p.Wait())
+		}
+	})
+
+	t.Run("NotWaited", func(t *testing.T) {
+		var p *exec.Cmd
+		defer func() { as.Error(t, p.Wait()) }()
+		// watchers exit once ctx done, though process is not waited yet
+		defer as.NoGoroutineLeak(t)()
+		ctx, cancel := context.WithCancel(context.Background())
+		var err error
+		p, err = Start("sleep", StartWithArgs("10"), StartWithContext(ctx),
+			StartWithGracefulStop(syscall.SIGTERM, time.Millisecond*100))
+		as.NoError(t, err)
+		cancel()
+	})
+
+	t.Run("Group", func(t *testing.T) {
+		pr, pw, err := os.Pipe()
+		as.NoError(t, err)
+		defer pr.Close()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		// the grandchild holds the pipe until it is stopped
+		p, err := Start("sh", StartWithArgs("-c", "sleep 10 & wait"),
+			StartWithContext(ctx), StartWithStdout(pw), StartWithDetach(),
+			StartWithGracefulStop(syscall.SIGTERM, time.Second*5))
+		pw.Close()
+		as.NoError(t, err)
+
+		cancel()
+		done := make(chan struct{})
+		go func() {
+			_, _ = ioutil.ReadAll(pr)
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(time.Second * 3):
+			t.Fatal("process group not stopped")
+		}
+		as.Error(t, p.Wait())
+		as.Equal(t, p.ProcessState.Sys().(syscall.WaitStatus).Signal(), syscall.SIGTERM)
+	})
+}
@@ -0,0 +1,307 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/elvinchan/util-collects/log"
+	"github.com/elvinchan/util-collects/retry/backoff"
+)
+
+// SupervisorState is the state of a Supervisor.
+type SupervisorState int32
+
+const (
+	// SupervisorStopped means no process is running, and will not be
+	// restarted.
+	SupervisorStopped SupervisorState = iota
+	// SupervisorRunning means the process is running.
+	SupervisorRunning
+	// SupervisorBackoff means the process exited, and is waiting to be
+	// restarted.
+	SupervisorBackoff
+)
+
+func (s SupervisorState) String() string {
+	switch s {
+	case SupervisorStopped:
+		return "stopped"
+	case SupervisorRunning:
+		return "running"
+	case SupervisorBackoff:
+		return "backoff"
+	default:
+		return "unknown"
+	}
+}
+
+// SupervisorStatus is a snapshot of the status of a Supervisor.
+type SupervisorStatus struct {
+	State SupervisorState
+	// Pid is the pid of running process, or 0 if not running.
+	Pid int
+	// Restarts is the times of process restarted.
+	Restarts uint
+	// LastExit is the state of the last exited process, or nil if none
+	// exited.
+	LastExit *os.ProcessState
+	// LastError is the error of the last start or exit of process, or nil
+	// if it exited successfully.
+	LastError error
+}
+
+// Supervisor runs a long-running process, and restarts it on exit.
+type Supervisor struct {
+	name      string
+	startOpts []StartOption
+	backoff   backoff.BackoffFunc
+	stable    time.Duration
+	logger    log.Logger
+	stop      *gracefulStop
+
+	mu     sync.Mutex
+	status SupervisorStatus
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// SupervisorOption specified option for Supervisor.
+type SupervisorOption func(*Supervisor)
+
+// SupervisorWithStart specified options for starting process, the context
+// and graceful stop of which are ignored, since they are managed by
+// Supervisor.
+func SupervisorWithStart(opts ...StartOption) SupervisorOption {
+	return func(s *Supervisor) {
+		s.startOpts = append(s.startOpts, opts...)
+	}
+}
+
+// SupervisorWithBackoff specified the delay before each restart, called with
+// the times of restart tried since process last ran stably, starting from 1.
+// Defaults to 1 second constantly.
+func SupervisorWithBackoff(bf backoff.BackoffFunc) SupervisorOption {
+	return func(s *Supervisor) {
+		s.backoff = bf
+	}
+}
+
+// SupervisorWithStableRun specified how long process runs to be considered
+// stable, after which the times of restart passed to backoff is reset.
+// Defaults to 1 minute.
+func SupervisorWithStableRun(d time.Duration) SupervisorOption {
+	return func(s *Supervisor) {
+		s.stable = d
+	}
+}
+
+// SupervisorWithLogger specified the logger which stdout and stderr of
+// process are forwarded to line by line, in Info and Warn level
+// respectively, and also the restarts of process are logged to.
+func SupervisorWithLogger(logger log.Logger) SupervisorOption {
+	return func(s *Supervisor) {
+		s.logger = logger
+	}
+}
+
+// SupervisorWithGracefulStop specified how process is stopped by Stop,
+// defaults to SIGTERM with 10 seconds grace period.
+func SupervisorWithGracefulStop(sig os.Signal, grace time.Duration) SupervisorOption {
+	return func(s *Supervisor) {
+		s.stop = &gracefulStop{sig: sig, grace: grace}
+	}
+}
+
+// NewSupervisor create a Supervisor of command name, which is not started
+// until Start is called.
+func NewSupervisor(name string, opts ...SupervisorOption) *Supervisor {
+	s := &Supervisor{
+		name:    name,
+		backoff: backoff.Constant(time.Second),
+		stable:  time.Minute,
+		logger:  log.NewDiscardLogger(),
+		stop:    &gracefulStop{sig: syscall.SIGTERM, grace: time.Second * 10},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Start starts process in background, and keeps restarting it on exit until
+// Stop is called. It returns error if the first start failed, or supervisor
+// is already started.
+func (s *Supervisor) Start() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.done != nil {
+		return errors.New("supervisor already started")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	p, err := s.start(ctx)
+	if err != nil {
+		cancel()
+		s.status.LastError = err
+		return err
+	}
+	s.cancel = cancel
+	s.done = make(chan struct{})
+	go s.supervise(ctx, p, s.done)
+	return nil
+}
+
+// Stop stops process gracefully and waits for it to exit, then the
+// supervisor can be started again.
+func (s *Supervisor) Stop() {
+	s.mu.Lock()
+	cancel, done := s.cancel, s.done
+	s.mu.Unlock()
+	if done == nil {
+		return
+	}
+	cancel()
+	<-done
+
+	s.mu.Lock()
+	s.cancel, s.done = nil, nil
+	s.mu.Unlock()
+}
+
+// Status returns the current status of supervisor.
+func (s *Supervisor) Status() SupervisorStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.status
+}
+
+// start starts process, and must be called with mu held.
+func (s *Supervisor) start(ctx context.Context) (*process, error) {
+	p := &process{
+		stdout: &lineLogger{log: s.logger.WithField("stream", "stdout").Info},
+		stderr: &lineLogger{log: s.logger.WithField("stream", "stderr").Warn},
+	}
+	opts := append(append([]StartOption(nil), s.startOpts...),
+		StartWithStdout(p.stdout),
+		StartWithStderr(p.stderr),
+		StartWithContext(ctx),
+		StartWithGracefulStop(s.stop.sig, s.stop.grace),
+	)
+	cmd, release, err := newStarter(opts).start(s.name)
+	if err != nil {
+		return nil, err
+	}
+	p.cmd, p.release, p.started = cmd, release, time.Now()
+	s.status.State = SupervisorRunning
+	s.status.Pid = cmd.Process.Pid
+	return p, nil
+}
+
+func (s *Supervisor) supervise(ctx context.Context, p *process, done chan struct{}) {
+	defer close(done)
+	// attempts is the times of restart tried since process last ran stably,
+	// including the failed ones
+	var attempts uint
+	for {
+		if p != nil {
+			err := p.cmd.Wait()
+			p.release()
+			p.stdout.flush()
+			p.stderr.flush()
+			if time.Since(p.started) >= s.stable {
+				attempts = 0
+			}
+			s.mu.Lock()
+			s.status.Pid = 0
+			s.status.LastExit = p.cmd.ProcessState
+			s.status.LastError = err
+			s.mu.Unlock()
+			if err != nil {
+				s.logger.WithError(err).Warn("process %s exited", s.name)
+			} else {
+				s.logger.Info("process %s exited", s.name)
+			}
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		s.mu.Lock()
+		s.status.State = SupervisorBackoff
+		s.mu.Unlock()
+		attempts++
+		delay := s.backoff(attempts)
+		s.logger.Info("restart process %s in %s", s.name, delay)
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+		case <-timer.C:
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		s.mu.Lock()
+		var err error
+		p, err = s.start(ctx)
+		if err != nil {
+			s.status.LastError = err
+		} else {
+			s.status.Restarts++
+		}
+		s.mu.Unlock()
+		if err != nil {
+			s.logger.WithError(err).Error("failed to restart process %s", s.name)
+		}
+	}
+	s.mu.Lock()
+	s.status.State = SupervisorStopped
+	s.mu.Unlock()
+}
+
+// process is a process started by Supervisor.
+type process struct {
+	cmd *exec.Cmd
+	// release releases the watcher of graceful stop once cmd is waited.
+	release        func()
+	started        time.Time
+	stdout, stderr *lineLogger
+}
+
+// lineLogger logs each line written to it.
+type lineLogger struct {
+	mu  sync.Mutex
+	log func(format string, v ...interface{})
+	buf []byte
+}
+
+func (l *lineLogger) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.buf = append(l.buf, p...)
+	for {
+		i := bytes.IndexByte(l.buf, '\n')
+		if i < 0 {
+			break
+		}
+		l.log("%s", bytes.TrimSuffix(l.buf[:i], []byte("\r")))
+		l.buf = l.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+// flush logs the rest which is not terminated by newline.
+func (l *lineLogger) flush() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.buf) > 0 {
+		l.log("%s", l.buf)
+		l.buf = nil
+	}
+}
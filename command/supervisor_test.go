@@ -0,0 +1,178 @@
+//go:build !windows
+// +build !windows
+
+package command
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/elvinchan/util-collects/as"
+	"github.com/elvinchan/util-collects/log"
+	"github.com/elvinchan/util-collects/retry/backoff"
+)
+
+// recordSink records the output of logger as lines like "INFO stdout hello".
+type recordSink struct {
+	mu     *sync.Mutex
+	lines  *[]string
+	stream interface{}
+}
+
+func newRecordSink() *recordSink {
+	return &recordSink{mu: &sync.Mutex{}, lines: &[]string{}}
+}
+
+func (s *recordSink) WithField(key string, value interface{}) log.Sink {
+	clone := *s
+	if key == "stream" {
+		clone.stream = value
+	}
+	return &clone
+}
+
+func (s *recordSink) Output(ctx context.Context, prefix string, lvl log.Level, msg string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stream != nil {
+		msg = fmt.Sprintf("%s %s", s.stream, msg)
+	}
+	*s.lines = append(*s.lines, fmt.Sprintf("%s %s", lvl, msg))
+}
+
+func (s *recordSink) has(line string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, l := range *s.lines {
+		if l == line {
+			return true
+		}
+	}
+	return false
+}
+
+func TestSupervisor(t *testing.T) {
+	t.Run("Restart", func(t *testing.T) {
+		sink := newRecordSink()
+		s := NewSupervisor("sh",
+			SupervisorWithStart(StartWithArgs("-c", "echo hello; echo world 1>&2; exit 1")),
+			// backoff long enough after 2 restarts, to stop in backoff state
+			SupervisorWithBackoff(backoff.Explicit(0, time.Millisecond*10, time.Millisecond*10, time.Hour)),
+			SupervisorWithLogger(log.NewBasicLogger(sink)),
+		)
+		as.NoError(t, s.Start())
+		as.Error(t, s.Start())
+		as.Eventually(t, func(ctx context.Context) bool {
+			st := s.Status()
+			return st.Restarts == 2 && st.State == SupervisorBackoff
+		}, time.Second*5, time.Millisecond*10)
+		s.Stop()
+
+		st := s.Status()
+		as.Equal(t, st.State, SupervisorStopped)
+		as.Equal(t, st.Pid, 0)
+		as.Equal(t, st.LastExit.ExitCode(), 1)
+		as.ErrorContains(t, st.LastError, "exit status 1")
+		as.True(t, sink.has("INFO stdout hello"))
+		as.True(t, sink.has("WARN stderr world"))
+		as.True(t, sink.has("INFO restart process sh in 10ms"))
+	})
+
+	t.Run("StableRun", func(t *testing.T) {
+		var mu sync.Mutex
+		var retries []uint
+		s := NewSupervisor("sh",
+			SupervisorWithStart(StartWithArgs("-c", "sleep 0.1; exit 1")),
+			SupervisorWithBackoff(func(n uint) time.Duration {
+				mu.Lock()
+				defer mu.Unlock()
+				retries = append(retries, n)
+				return time.Millisecond
+			}),
+			SupervisorWithStableRun(time.Millisecond*50),
+		)
+		as.NoError(t, s.Start())
+		as.Eventually(t, func(ctx context.Context) bool {
+			return s.Status().Restarts >= 3
+		}, time.Second*5, time.Millisecond*10)
+		s.Stop()
+
+		mu.Lock()
+		defer mu.Unlock()
+		// reset after each run longer than stable run
+		as.Equal(t, retries[:3], []uint{1, 1, 1})
+	})
+
+	t.Run("Stop", func(t *testing.T) {
+		sink := newRecordSink()
+		s := NewSupervisor("sh",
+			SupervisorWithStart(StartWithArgs("-c", "trap 'echo term; exit 0' TERM; "+
+				"echo ready; while :; do sleep 0.01; done")),
+			SupervisorWithLogger(log.NewBasicLogger(sink)),
+		)
+		as.NoError(t, s.Start())
+		as.Eventually(t, func(ctx context.Context) bool {
+			return sink.has("INFO stdout ready")
+		}, time.Second*5, time.Millisecond*10)
+		st := s.Status()
+		as.Equal(t, st.State, SupervisorRunning)
+		as.NotEqual(t, st.Pid, 0)
+		s.Stop()
+
+		st = s.Status()
+		as.Equal(t, st.State, SupervisorStopped)
+		as.Equal(t, st.Restarts, uint(0))
+		as.Equal(t, st.LastExit.ExitCode(), 0)
+		as.NoError(t, st.LastError)
+		as.True(t, sink.has("INFO stdout term"))
+
+		// started again after stopped
+		as.NoError(t, s.Start())
+		as.Equal(t, s.Status().State, SupervisorRunning)
+		s.Stop()
+	})
+
+	t.Run("Escalate", func(t *testing.T) {
+		sink := newRecordSink()
+		s := NewSupervisor("sh",
+			SupervisorWithStart(StartWithArgs("-c", "trap '' TERM; "+
+				"echo ready; while :; do sleep 0.01; done")),
+			SupervisorWithLogger(log.NewBasicLogger(sink)),
+			SupervisorWithGracefulStop(syscall.SIGTERM, time.Millisecond*100),
+		)
+		as.NoError(t, s.Start())
+		as.Eventually(t, func(ctx context.Context) bool {
+			return sink.has("INFO stdout ready")
+		}, time.Second*5, time.Millisecond*10)
+		s.Stop()
+
+		st := s.Status()
+		as.Equal(t, st.State, SupervisorStopped)
+		as.Equal(t, st.LastExit.Sys().(syscall.WaitStatus).Signal(), syscall.SIGKILL)
+	})
+
+	t.Run("Release", func(t *testing.T) {
+		defer as.NoGoroutineLeak(t)()
+		s := NewSupervisor("sh",
+			SupervisorWithStart(StartWithArgs("-c", "exit 1")),
+			SupervisorWithBackoff(backoff.Explicit(time.Millisecond)),
+			SupervisorWithLogger(log.NewBasicLogger(newRecordSink())),
+		)
+		as.NoError(t, s.Start())
+		as.Eventually(t, func(ctx context.Context) bool {
+			return s.Status().Restarts >= 5
+		}, time.Second*5, time.Millisecond*10)
+		s.Stop()
+	})
+
+	t.Run("StartFailed", func(t *testing.T) {
+		s := NewSupervisor("command-not-exists")
+		as.Error(t, s.Start())
+		as.Equal(t, s.Status().State, SupervisorStopped)
+		as.Error(t, s.Status().LastError)
+	})
+}